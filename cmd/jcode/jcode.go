@@ -1,22 +1,36 @@
+// Command jcode checks that sentinel errors have jettison codes in the expected
+// format, using jcode.Analyzer, and that packages don't share codes, fixing them
+// with -rewrite. It can
+// also write a catalogue of the codes, e.g. for API documentation, which lists the
+// RPCs whose comments in the proto definitions mention each error:
+//
+//	jcode -rewrite ./...
+//	jcode -registry errors.md -proto api/service.proto ./...
 package main
 
 import (
 	"bytes"
-	"encoding/base64"
-	"encoding/hex"
+	"cmp"
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
 	"go/token"
-	"math/rand"
+	"go/types"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
 
-	"github.com/dave/dst"
-	"github.com/dave/dst/decorator"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
 
 	"github.com/luno/jettison/errors"
 	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/jcode"
 )
 
 const (
@@ -24,40 +38,34 @@ const (
 	statusHelp        = 2
 	statusInvalidFile = 3
 
-	jetPath = "\"github.com/luno/jettison/errors\""
-	jPath   = "\"github.com/luno/jettison/j\""
+	// adHocPkgPath is the import path of the package of files outside a module
+	adHocPkgPath = "command-line-arguments"
 )
 
 var (
-	rewrite = flag.Bool("rewrite", false, "rewrite source files")
-	cFormat = flag.String("format", "err_hex_16", "error code format")
+	rewrite  = flag.Bool("rewrite", false, "rewrite source files")
+	cFormat  = flag.String("format", jcode.FormatErrHex.Name, "error code format, one of "+strings.Join(jcode.FormatNames(), ", "))
+	registry = flag.String("registry", "", "write a catalogue of the code, message and source of each sentinel error to this file, either .json, .md or .go")
+	protos   protoFlags
 )
 
-type codeFormat int
+type protoFlags []string
 
-const (
-	formatBase64 codeFormat = 1
-	formatErrHex codeFormat = 2
-)
-
-type formatParams struct {
-	format codeFormat
-	label  string
-	gen    func(pkg, variable string) string
-	valid  func(pkg, variable, code string) bool
+func (p *protoFlags) String() string {
+	return strings.Join(*p, ",")
 }
 
-var fmtParams = []formatParams{
-	{
-		formatBase64, "base64_8", fmtBase64, validBase64,
-	},
-	{
-		formatErrHex, "err_hex_16", fmtErrHex, validErrHex,
-	},
+func (p *protoFlags) Set(s string) error {
+	*p = append(*p, s)
+	return nil
 }
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	flag.Var(&protos, "proto", "list the RPCs in this proto file with the errors that their comments mention, may be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [files or packages, e.g. ./...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 	if flag.NArg() == 0 {
 		fmt.Fprintln(os.Stderr, "No file provided")
@@ -65,239 +73,420 @@ func main() {
 		os.Exit(statusHelp)
 	}
 
-	fp := getFormatParams()
+	format, ok := jcode.FormatByName(*cFormat)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Unknown format")
+		flag.Usage()
+		os.Exit(statusHelp)
+	}
 
-	fail := false
-	for _, file := range flag.Args() {
-		res, err := checkFile(file, fp, *rewrite)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%+v\n", err)
-			os.Exit(statusError)
-		}
+	files, err := findFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(statusError)
+	}
+	results, err := checkFiles(files, format, *rewrite)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(statusError)
+	}
+
+	var fail, rewritten bool
+	for i, res := range results {
 		if res.pass {
 			continue
 		}
 		for _, msg := range res.msgs {
 			fmt.Println("  ", msg)
 		}
-		if *rewrite {
-			err := os.WriteFile(file, res.out, 0o644)
+		if !*rewrite || !res.fixed {
+			fail = true
+		}
+		if *rewrite && res.out != nil {
+			err := os.WriteFile(files[i].path, res.out, 0o644)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%+v\n", err)
 				os.Exit(statusError)
 			}
-		} else {
-			fail = true
+			rewritten = true
 		}
 	}
 	if fail {
 		os.Exit(statusInvalidFile)
 	}
-}
 
-func getFormatParams() formatParams {
-	var fp formatParams
-	for _, p := range fmtParams {
-		if p.label == *cFormat {
-			fp = p
+	if *registry != "" {
+		if rewritten {
+			// Load the files again for the fixed codes
+			files, err = findFiles(flag.Args())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%+v\n", err)
+				os.Exit(statusError)
+			}
+		}
+		entries, err := buildRegistry(files, protos)
+		if err == nil {
+			err = writeRegistryFile(*registry, entries)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%+v\n", err)
+			os.Exit(statusError)
 		}
 	}
-	if fp.label == "" {
-		fmt.Fprintln(os.Stderr, "Unknown format")
-		flag.Usage()
-		os.Exit(statusHelp)
-	}
-	return fp
 }
 
-type checkResult struct {
-	pass bool
-	msgs []string
-	out  []byte
+// sourceFile is a file to check and its type checked package
+type sourceFile struct {
+	path   string
+	pkg    *packages.Package
+	syntax *ast.File
 }
 
-func checkFile(file string, fp formatParams, rewrite bool) (checkResult, error) {
-	var (
-		msgs          []string
-		jettisonPkg   string
-		pkg           string
-		importGenDecl *dst.GenDecl
-		foundJ        bool
-	)
+func (sf sourceFile) tokenFile() *token.File {
+	return sf.pkg.Fset.File(sf.syntax.FileStart)
+}
 
-	fset := token.NewFileSet()
-	f, err := decorator.ParseFile(fset, file, nil, 0)
-	if err != nil {
-		return checkResult{}, err
+// importPath returns the import path of the file's package,
+// or its name when the file is outside a module
+func (sf sourceFile) importPath() string {
+	if sf.pkg.PkgPath == adHocPkgPath {
+		return sf.pkg.Name
 	}
+	return sf.pkg.PkgPath
+}
 
-	for _, decl := range f.Decls {
-		gd, ok := decl.(*dst.GenDecl)
-		if !ok {
-			continue
+// findFiles returns the files given in args, args which aren't
+// files are loaded as package patterns, e.g. ./...
+func findFiles(args []string) ([]sourceFile, error) {
+	var paths, patterns []string
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, arg)
+		} else {
+			patterns = append(patterns, arg)
 		}
-		if gd.Tok == token.IMPORT {
-			// import block
-			importGenDecl = gd
-			for _, s := range gd.Specs {
-				is, ok := s.(*dst.ImportSpec)
-				if !ok {
-					return checkResult{}, errors.Wrap(err, "Unexpected non import spec",
-						j.KV("file", file))
-				}
-				alias := "errors"
-				if is.Name != nil {
-					alias = is.Name.Name
-				}
-				if string(is.Path.Value) == jetPath {
-					jettisonPkg = alias
-				} else if string(is.Path.Value) == jPath {
-					foundJ = true
-				}
-			}
-			continue
+	}
+
+	var files []sourceFile
+	if len(paths) > 0 {
+		filePatterns := make([]string, 0, len(paths))
+		for _, path := range paths {
+			filePatterns = append(filePatterns, "file="+path)
+		}
+		pkgs, err := loadPackages(filePatterns)
+		if err != nil {
+			return nil, err
+		}
+		byName := make(map[string]sourceFile)
+		for _, sf := range packageFiles(pkgs) {
+			byName[sf.path] = sf
 		}
-		if gd.Tok == token.VAR {
-			// var block
-			for _, s := range gd.Specs {
-				// Check for jettison sentinel errors: var ErrFoo = errors.New("foo").
-				vp, ok := s.(*dst.ValueSpec)
-				if !ok {
-					continue
-				}
-				if len(vp.Names) != 1 || len(vp.Values) != 1 {
-					continue
-				}
-				ce, ok := vp.Values[0].(*dst.CallExpr)
-				if !ok {
-					continue
-				}
-				varName := vp.Names[0].Name
-				se, ok := ce.Fun.(*dst.SelectorExpr)
-				if !ok {
-					continue
-				}
-				if fmt.Sprint(se.X) != jettisonPkg || se.Sel.Name != "New" {
-					continue
-				}
-
-				// Check code
-				msg, err := checkInstance(jettisonPkg, pkg, varName, ce, fp)
-				if err != nil {
-					return checkResult{}, errors.Wrap(err, "error checking instance",
-						j.MKV{"file": file, "var": varName})
-				}
-				if msg != "" {
-					msgs = append(msgs, fmt.Sprintf("%s: %s: %s (fixed)", file, varName, msg))
-				}
+		for _, path := range paths {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return nil, errors.Wrap(err, "error finding file", j.KV("file", path))
+			}
+			sf, ok := byName[abs]
+			if !ok {
+				return nil, errors.New("file isn't part of a package, it may be excluded by build constraints",
+					j.KV("file", path))
 			}
+			sf.path = path
+			files = append(files, sf)
 		}
 	}
+	if len(patterns) > 0 {
+		pkgs, err := loadPackages(patterns)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, packageFiles(pkgs)...)
+	}
+	return files, nil
+}
 
-	var buf bytes.Buffer
-	if len(msgs) > 0 && rewrite {
-		if !foundJ {
-			// Add jettison/j import
-			importGenDecl.Specs = append(importGenDecl.Specs, &dst.ImportSpec{
-				Path: &dst.BasicLit{Value: jPath},
-			})
+// loadPackages loads the packages with their dependencies, which checker.Analyze needs
+func loadPackages(patterns []string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, patterns...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading packages", j.KV("patterns", strings.Join(patterns, " ")))
+	}
+	for _, p := range pkgs {
+		for _, e := range p.Errors {
+			return nil, errors.New("error loading package", j.MKV{"package": p.PkgPath, "error": e.Error()})
 		}
-		if err := decorator.Fprint(&buf, f); err != nil {
-			return checkResult{}, err
+	}
+	return pkgs, nil
+}
+
+// packageFiles returns the Go files of the packages, named by their absolute paths
+func packageFiles(pkgs []*packages.Package) []sourceFile {
+	var files []sourceFile
+	for _, p := range pkgs {
+		for _, f := range p.Syntax {
+			sf := sourceFile{pkg: p, syntax: f}
+			sf.path = sf.tokenFile().Name()
+			if slices.Contains(p.GoFiles, sf.path) {
+				// Not generated by cgo
+				files = append(files, sf)
+			}
 		}
 	}
+	return files
+}
 
-	return checkResult{
-		pass: len(msgs) == 0,
-		msgs: msgs,
-		out:  buf.Bytes(),
-	}, nil
+type checkResult struct {
+	pass bool
+	// fixed is true when each of the problems has been fixed in out
+	fixed bool
+	msgs  []string
+	out   []byte
 }
 
-func checkInstance(jettisonPkg string, pkg string, varName string, ce *dst.CallExpr, format formatParams) (string, error) {
-	if len(ce.Args) < 2 {
-		code := format.gen(pkg, varName)
-		ce.Args = append(ce.Args, makeCodeCall(code))
-		return "missing jettison code", nil
-	}
-	if len(ce.Args) > 2 {
-		return "", errors.New("only single jettison code option supported")
+// sentinel is a package level error declared with errors.New
+type sentinel struct {
+	file string
+	pkg  string
+	name string
+	// msg is the message of the sentinel, when it's a constant
+	msg  string
+	doc  string
+	line int
+	// code is the code of the sentinel after any fixes, when it's a constant
+	code string
+	// lit is the code when it's a literal, which can be replaced to fix it
+	lit *ast.BasicLit
+}
+
+type fileCheck struct {
+	sourceFile
+	sentinels []*sentinel
+	msgs      []string
+	edits     []analysis.TextEdit
+	unfixed   bool
+}
+
+// report adds a problem with the file, which the edits fix
+func (fc *fileCheck) report(msg string, edits []analysis.TextEdit) {
+	if len(edits) == 0 {
+		fc.unfixed = true
+		fc.msgs = append(fc.msgs, fmt.Sprintf("%s: %s", fc.path, msg))
+		return
 	}
-	ce2, ok := ce.Args[1].(*dst.CallExpr)
-	if !ok {
-		return "", errors.New("invalid second argument, expect j.C")
+	fc.edits = append(fc.edits, edits...)
+	fc.msgs = append(fc.msgs, fmt.Sprintf("%s: %s (fixed)", fc.path, msg))
+}
+
+func checkFile(file string, format jcode.Format, rewrite bool) (checkResult, error) {
+	files, err := findFiles([]string{file})
+	if err != nil {
+		return checkResult{}, err
 	}
-	se2, ok := ce2.Fun.(*dst.SelectorExpr)
-	if !ok {
-		return "", errors.New("invalid second argument, expect j.C")
+	res, err := checkFiles(files, format, rewrite)
+	if err != nil {
+		return checkResult{}, err
 	}
-	selExp := fmt.Sprintf("%s.%s", se2.X, se2.Sel)
-	if selExp != jettisonPkg+".WithCode" && selExp != "j.C" {
-		return "", errors.New("invalid second argument, expect j.C")
+	return res[0], nil
+}
+
+// checkFiles checks the sentinels in each file with jcode.Analyzer, then checks
+// that sentinels in different packages don't use the same code, otherwise errors.Is
+// would treat them as the same error. The first sentinel to use a code keeps it
+// and the sentinels in other packages are given new codes. Sentinels in the
+// same package may share a code, e.g. to alias an error.
+func checkFiles(files []sourceFile, format jcode.Format, rewrite bool) ([]checkResult, error) {
+	var (
+		checks = make([]*fileCheck, 0, len(files))
+		byFile = make(map[*token.File]*fileCheck)
+		pkgs   []*packages.Package
+	)
+	for _, sf := range files {
+		if sf.pkg.PkgPath == adHocPkgPath &&
+			(format.Name == jcode.FormatHash.Name || format.Name == jcode.FormatPkgVar.Name) {
+			// The codes would differ from those generated for the package's import path
+			return nil, errors.New("import path of file is unknown, which is needed for the format",
+				j.MKV{"file": sf.path, "format": format.Name})
+		}
+		fc := &fileCheck{sourceFile: sf, sentinels: sentinelsOf(sf)}
+		checks = append(checks, fc)
+		byFile[sf.tokenFile()] = fc
+		if !slices.Contains(pkgs, sf.pkg) {
+			pkgs = append(pkgs, sf.pkg)
+		}
 	}
 
-	bl, ok := ce2.Args[0].(*dst.BasicLit)
-	if !ok {
-		return "", errors.New("invalid code argument, expect string")
+	graph, err := checker.Analyze([]*analysis.Analyzer{jcode.NewAnalyzer(format)}, pkgs, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error analysing packages")
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return nil, errors.Wrap(act.Err, "error analysing package", j.KV("package", act.Package.PkgPath))
+		}
+		for _, d := range act.Diagnostics {
+			fc, ok := byFile[act.Package.Fset.File(d.Pos)]
+			if !ok {
+				// Another file in the package of a file given directly
+				continue
+			}
+			var edits []analysis.TextEdit
+			if len(d.SuggestedFixes) > 0 {
+				edits = d.SuggestedFixes[0].TextEdits
+			}
+			fc.report(d.Message, edits)
+		}
 	}
 
-	if !format.valid(pkg, varName, strings.Trim(bl.Value, "\"")) {
-		code := format.gen(pkg, varName)
-		ce.Args[1] = makeCodeCall(code)
-		return "incorrect jettison code", nil
+	index := make(map[string]*sentinel)
+	for _, fc := range checks {
+		for _, s := range fc.sentinels {
+			if s.code == "" || !format.Valid(s.pkg, s.name, s.code) {
+				// The analyzer has reported it
+				continue
+			}
+			first, ok := index[s.code]
+			if !ok {
+				index[s.code] = s
+				continue
+			}
+			if samePackage(first, s) {
+				continue
+			}
+			code, err := uniqueCode(index, s, format)
+			if err != nil {
+				return nil, err
+			}
+			var edits []analysis.TextEdit
+			if s.lit != nil {
+				edits = []analysis.TextEdit{{
+					Pos:     s.lit.Pos(),
+					End:     s.lit.End(),
+					NewText: []byte(strconv.Quote(code)),
+				}}
+			}
+			s.code = code
+			index[code] = s
+			fc.report(fmt.Sprintf("%s: duplicate jettison code, also used by %s", s.name, first.qualifiedName()), edits)
+		}
 	}
 
-	return "", nil
+	results := make([]checkResult, 0, len(checks))
+	for _, fc := range checks {
+		var out []byte
+		if len(fc.edits) > 0 && rewrite {
+			out, err = fc.apply()
+			if err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, checkResult{
+			pass:  len(fc.msgs) == 0,
+			fixed: !fc.unfixed,
+			msgs:  fc.msgs,
+			out:   out,
+		})
+	}
+	return results, nil
 }
 
-func makeCodeCall(code string) *dst.CallExpr {
-	codeExp := &dst.SelectorExpr{
-		X:   dst.NewIdent("j"),
-		Sel: dst.NewIdent("C"),
+// apply returns the source of the file with the edits, formatted
+func (fc *fileCheck) apply() ([]byte, error) {
+	tf := fc.tokenFile()
+	src, err := os.ReadFile(tf.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading file", j.KV("file", fc.path))
+	}
+	edits := slices.Clone(fc.edits)
+	slices.SortStableFunc(edits, func(a, b analysis.TextEdit) int {
+		return cmp.Compare(a.Pos, b.Pos)
+	})
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	for _, e := range edits {
+		start, end := tf.Offset(e.Pos), tf.Offset(e.End)
+		if start < last {
+			return nil, errors.New("overlapping fixes", j.MKV{"file": fc.path, "offset": start})
+		}
+		buf.Write(src[last:start])
+		buf.Write(e.NewText)
+		last = end
 	}
-	return &dst.CallExpr{
-		Fun:  codeExp,
-		Args: []dst.Expr{dst.NewIdent(fmt.Sprintf("\"%s\"", code))},
+	buf.Write(src[last:])
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "error formatting fixed file", j.KV("file", fc.path))
 	}
+	return out, nil
 }
 
-// fmtBase64 returns a base64 string of length 8; which is equivalent to a random uint48.
-func fmtBase64(_, _ string) string {
-	b := make([]byte, 6)
-	_, err := rand.Read(b)
-	if err != nil {
-		return "sorry error"
+// uniqueCode generates a code for the sentinel which isn't in the index
+func uniqueCode(index map[string]*sentinel, s *sentinel, format jcode.Format) (string, error) {
+	for range 10 {
+		code := format.Gen(s.pkg, s.name)
+		if index[code] == nil {
+			return code, nil
+		}
 	}
-	return base64.RawURLEncoding.EncodeToString(b)
+	return "", errors.New("failed to generate a unique code", j.MKV{"file": s.file, "var": s.name})
 }
 
-func validBase64(_, _, code string) bool {
-	if len(code) != 8 {
-		return false
+// samePackage returns true if the sentinels are declared in the same package,
+// files outside a module use their package name
+func samePackage(a, b *sentinel) bool {
+	return a.pkg == b.pkg
+}
+
+func (s *sentinel) qualifiedName() string {
+	return s.pkg + "." + s.name
+}
+
+// sentinelsOf returns the sentinels in the file, found by jcode.FindSentinels
+func sentinelsOf(sf sourceFile) []*sentinel {
+	info := sf.pkg.TypesInfo
+	var sentinels []*sentinel
+	for _, s := range jcode.FindSentinels(info, sf.syntax) {
+		code, _ := s.Code(info)
+		var lit *ast.BasicLit
+		if s.CodeCall != nil && len(s.CodeCall.Args) == 1 {
+			lit, _ = s.CodeCall.Args[0].(*ast.BasicLit)
+		}
+		sentinels = append(sentinels, &sentinel{
+			file: sf.path,
+			pkg:  sf.importPath(),
+			name: s.Name.Name,
+			msg:  messageOf(info, s.Call),
+			doc:  docOf(s),
+			line: sf.pkg.Fset.Position(s.Name.Pos()).Line,
+			code: code,
+			lit:  lit,
+		})
 	}
-	_, err := base64.RawURLEncoding.DecodeString(code)
-	return err == nil
+	return sentinels
 }
 
-// fmtErrHex returns string that matches the following regex "ERR_[0-9a-f]{16}",
-// ie. "ERR_" followed by random 16 character hex string.
-func fmtErrHex(_, _ string) string {
-	b := make([]byte, 8)
-	_, err := rand.Read(b)
-	if err != nil {
-		return "sorry error"
+// docOf returns the text of the doc comment of the var spec,
+// or of its declaration when it's not in a var block
+func docOf(s jcode.Sentinel) string {
+	doc := s.Spec.Doc
+	if !s.Decl.Lparen.IsValid() {
+		doc = s.Decl.Doc
+	}
+	if doc == nil {
+		return ""
 	}
-	return fmt.Sprintf("ERR_%x", b)
+	return strings.Join(strings.Fields(doc.Text()), " ")
 }
 
-func validErrHex(_, _, code string) bool {
-	s := strings.Split(code, "_")
-	if len(s) != 2 {
-		return false
+// messageOf returns the message given to errors.New, or an empty string if it isn't a constant
+func messageOf(info *types.Info, call *ast.CallExpr) string {
+	if len(call.Args) == 0 {
+		return ""
 	}
-	if s[0] != "ERR" {
-		return false
+	tv := info.Types[call.Args[0]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return ""
 	}
-	_, err := hex.DecodeString(s[1])
-	return err == nil
+	return constant.StringVal(tv.Value)
 }
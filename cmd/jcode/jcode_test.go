@@ -1,26 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/sebdah/goldie/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/luno/jettison/jcode"
 	"github.com/luno/jettison/jtest"
 )
 
 var writeGoldenFiles = flag.Bool("write-golden-files", false,
 	"Whether or not to overwrite golden files with test output.")
 
-var testFormat = formatParams{
-	valid: func(pkg, variable, code string) bool {
-		return code == "{code0}"
-	},
-	gen: func(pkg, variable string) string {
+var testFormat = jcode.Format{
+	Name: "test",
+	Gen: func(pkg, variable string) string {
 		return "{code1}"
 	},
+	Valid: func(pkg, variable, code string) bool {
+		return code == "{code0}"
+	},
 }
 
 func TestInOut(t *testing.T) {
@@ -31,25 +38,29 @@ func TestInOut(t *testing.T) {
 		{
 			name: "0",
 			msgs: []string{
-				"testdata/0.in: ErrInvalidCode1: incorrect jettison code (fixed)",
-				"testdata/0.in: ErrMissingCode: missing jettison code (fixed)",
-				"testdata/0.in: ErrInvalidCode2: incorrect jettison code (fixed)",
+				`testdata/0/0.go: ErrInvalidCode1: incorrect jettison code "Not a good code" (fixed)`,
+				"testdata/0/0.go: ErrMissingCode: missing jettison code (fixed)",
+				"testdata/0/0.go: ErrTrace: sentinel error has a stack trace, use j.C or errors.WithoutStackTrace (fixed)",
+				"testdata/0/0.go: Err1: missing jettison code (fixed)",
+				"testdata/0/0.go: Err2: missing jettison code (fixed)",
+				`testdata/0/0.go: ErrInvalidCode2: incorrect jettison code "Not a good code, on a new line" (fixed)`,
 			},
 		}, {
 			name: "1",
 			msgs: []string{
-				"testdata/1.in: errMissingCode: missing jettison code (fixed)",
+				"testdata/1/1.go: errMissingCode: missing jettison code (fixed)",
 			},
 		},
 	}
 
 	for _, test := range cases {
 		t.Run(test.name, func(t *testing.T) {
-			in := fmt.Sprintf("testdata/%s.in", test.name)
+			in := fmt.Sprintf("testdata/%s/%s.go", test.name, test.name)
 			out := fmt.Sprintf("testdata/%s.out", test.name)
 			res, err := checkFile(in, testFormat, true)
-			assert.NoError(t, err)
+			jtest.RequireNil(t, err)
 			assert.False(t, res.pass)
+			assert.True(t, res.fixed)
 			assert.EqualValues(t, test.msgs, res.msgs)
 			verifyOutput(t, out, res.out)
 		})
@@ -73,14 +84,149 @@ func verifyOutput(t *testing.T, golden string, output []byte) {
 	assert.Equal(t, string(contents), string(output))
 }
 
-func TestBase64(t *testing.T) {
-	uuid := fmtBase64("", "")
-	assert.Len(t, uuid, 8)
-	assert.True(t, validBase64("", "", uuid))
+func TestDuplicateCodes(t *testing.T) {
+	files, err := findFiles([]string{"./testdata/dups/..."})
+	jtest.RequireNil(t, err)
+	assert.Len(t, files, 2)
+
+	res, err := checkFiles(files, testFormat, true)
+	jtest.RequireNil(t, err)
+	assert.True(t, res[0].pass)
+	assert.False(t, res[1].pass)
+	assert.Len(t, res[1].msgs, 1)
+	assert.Contains(t, res[1].msgs[0],
+		"ErrCopied: duplicate jettison code, also used by github.com/luno/jettison/cmd/jcode/testdata/dups/a.ErrFirst (fixed)")
+	assert.Contains(t, string(res[1].out), `ErrCopied = errors.New("copied", errors.C("{code1}"))`)
+}
+
+func TestUnfixableCode(t *testing.T) {
+	res, err := checkFile("testdata/2/2.go", testFormat, true)
+	jtest.RequireNil(t, err)
+	assert.False(t, res.pass)
+	assert.False(t, res.fixed)
+	assert.Equal(t, []string{
+		`testdata/2/2.go: ErrConst: incorrect jettison code "Not a good code"`,
+	}, res.msgs)
+	assert.Nil(t, res.out)
+}
+
+func TestFindFilesResolvesPackage(t *testing.T) {
+	files, err := findFiles([]string{"./testdata/dups/a/a.go"})
+	jtest.RequireNil(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "./testdata/dups/a/a.go", files[0].path)
+	assert.Equal(t, "github.com/luno/jettison/cmd/jcode/testdata/dups/a", files[0].importPath())
+
+	// Codes are generated from the import path, the same as for ./...
+	pkgFiles, err := findFiles([]string{"./testdata/dups/a"})
+	jtest.RequireNil(t, err)
+	require.Len(t, pkgFiles, 1)
+	assert.Equal(t, pkgFiles[0].importPath(), files[0].importPath())
+}
+
+func TestPackageFormatsNeedImportPath(t *testing.T) {
+	// Files outside a module don't have an import path
+	src, err := os.ReadFile("testdata/dups/a/a.go")
+	jtest.RequireNil(t, err)
+	path := filepath.Join(t.TempDir(), "a.go")
+	jtest.RequireNil(t, os.WriteFile(path, src, 0o644))
+	files, err := findFiles([]string{path})
+	jtest.RequireNil(t, err)
+
+	for _, format := range jcode.Formats {
+		t.Run(format.Name, func(t *testing.T) {
+			_, err := checkFiles(files, format, false)
+			if format.Name == jcode.FormatHash.Name || format.Name == jcode.FormatPkgVar.Name {
+				assert.Error(t, err)
+			} else {
+				jtest.RequireNil(t, err)
+			}
+		})
+	}
+}
+
+//go:generate go test . -run TestRegistry -update
+
+func TestRegistry(t *testing.T) {
+	files, err := findFiles([]string{"./testdata/dups/a"})
+	jtest.RequireNil(t, err)
+	entries, err := buildRegistry(files, []string{"testdata/service.proto"})
+	jtest.RequireNil(t, err)
+
+	var js bytes.Buffer
+	jtest.RequireNil(t, writeJSONRegistry(&js, entries))
+	goldie.New(t).Assert(t, "registry_json", js.Bytes())
+
+	var gs bytes.Buffer
+	jtest.RequireNil(t, writeGoRegistry(&gs, "errcodes", entries))
+	goldie.New(t).Assert(t, "registry_go", gs.Bytes())
+
+	var md bytes.Buffer
+	writeMarkdownRegistry(&md, entries)
+	goldie.New(t).Assert(t, "registry_md", md.Bytes())
+}
+
+func TestParseProto(t *testing.T) {
+	rpcs, err := parseProtoFile("testdata/service.proto")
+	jtest.RequireNil(t, err)
+	assert.Equal(t, []rpcComment{
+		{name: "luno.example.Example/First", comment: "First returns ErrFirst."},
+		{name: "luno.example.Example/Alias", comment: "Alias may return a.ErrAlias trailing"},
+		{name: "luno.example.Example/None", comment: ""},
+	}, rpcs)
+}
+
+func TestCorrelateRPCs(t *testing.T) {
+	rpcs, err := parseProto(strings.NewReader(`
+package luno.accounts;
+
+service Accounts {
+  // Get returns ERR_4b1d7c2e9f0a3b68 when the account doesn't exist.
+  rpc Get(Request) returns (Response) {}
+
+  // Create may fail with accounts.ErrExists or ErrLimit
+  rpc Create(Request) returns (Response) {}
+
+  // Update may fail with ErrNotFound, which is ambiguous
+  rpc Update(Request) returns (Response) {}
+}
+`))
+	jtest.RequireNil(t, err)
+
+	entries := []registryEntry{
+		{Code: "ERR_4b1d7c2e9f0a3b68", Package: "example.com/accounts", Var: "ErrNotFound"},
+		{Code: "ERR_0c9e2a7d4f1b8e35", Package: "example.com/accounts", Var: "ErrExists"},
+		{Code: "ERR_7e3f1a9c0d2b6e54", Package: "example.com/accounts", Var: "ErrLimit"},
+		{Code: "ERR_2a8d5e1c7f0b3d96", Package: "example.com/users", Var: "ErrNotFound"},
+	}
+	correlateRPCs(entries, rpcs)
+
+	var got [][]string
+	for _, e := range entries {
+		got = append(got, e.RPCs)
+	}
+	assert.Equal(t, [][]string{
+		{"luno.accounts.Accounts/Get"},
+		{"luno.accounts.Accounts/Create"},
+		{"luno.accounts.Accounts/Create"},
+		nil,
+	}, got)
 }
 
-func TestErrHex4(t *testing.T) {
-	code := fmtErrHex("", "")
-	assert.Len(t, code, 4+16)
-	assert.True(t, validErrHex("", "", code))
+func TestWriteRegistryFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "errcodes")
+	jtest.RequireNil(t, os.Mkdir(dir, 0o755))
+	entries := []registryEntry{{Code: "ERR_1", Message: "one", Package: "a", Var: "ErrOne", Source: "a/a.go:3"}}
+
+	jtest.RequireNil(t, writeRegistryFile(filepath.Join(dir, "errcodes.go"), entries))
+	b, err := os.ReadFile(filepath.Join(dir, "errcodes.go"))
+	jtest.RequireNil(t, err)
+	assert.Contains(t, string(b), "package errcodes")
+
+	jtest.RequireNil(t, writeRegistryFile(filepath.Join(dir, "errcodes.json"), entries))
+	b, err = os.ReadFile(filepath.Join(dir, "errcodes.json"))
+	jtest.RequireNil(t, err)
+	assert.Contains(t, string(b), `"code": "ERR_1"`)
+
+	assert.Error(t, writeRegistryFile(filepath.Join(dir, "errcodes.txt"), entries))
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+var (
+	protoPackage = regexp.MustCompile(`^package\s+([\w.]+)\s*;`)
+	protoService = regexp.MustCompile(`^service\s+(\w+)`)
+	protoRPC     = regexp.MustCompile(`^rpc\s+(\w+)\s*\(`)
+)
+
+// rpcComment is an RPC in a proto service definition and the text of its comments
+type rpcComment struct {
+	name    string
+	comment string
+}
+
+// parseProtoFile returns the RPCs in the proto file
+func parseProtoFile(name string) ([]rpcComment, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "open proto file", j.KV("file", name))
+	}
+	defer f.Close()
+	rpcs, err := parseProto(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse proto file", j.KV("file", name))
+	}
+	return rpcs, nil
+}
+
+// parseProto returns the RPCs in the proto definition, named "package.Service/Method",
+// with the comments immediately before and on the same line as them
+func parseProto(r io.Reader) ([]rpcComment, error) {
+	var (
+		pkg, service string
+		comments     []string
+		rpcs         []rpcComment
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		code, comment, _ := strings.Cut(line, "//")
+		code, comment = strings.TrimSpace(code), strings.TrimSpace(comment)
+		if code == "" {
+			if comment != "" {
+				comments = append(comments, comment)
+			} else {
+				comments = nil
+			}
+			continue
+		}
+		if m := protoPackage.FindStringSubmatch(code); m != nil {
+			pkg = m[1] + "."
+		} else if m := protoService.FindStringSubmatch(code); m != nil {
+			service = m[1]
+		} else if m := protoRPC.FindStringSubmatch(code); m != nil {
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+			rpcs = append(rpcs, rpcComment{
+				name:    pkg + service + "/" + m[1],
+				comment: strings.Join(comments, " "),
+			})
+		}
+		comments = nil
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return rpcs, nil
+}
+
+// correlateRPCs adds the RPCs which mention each entry in their comments. An entry is
+// mentioned by its code, by its package name and variable, e.g. accounts.ErrNotFound,
+// or by its variable alone when no other entry has the same variable name.
+func correlateRPCs(entries []registryEntry, rpcs []rpcComment) {
+	vars := make(map[string]int)
+	for _, e := range entries {
+		vars[e.Var]++
+	}
+	for _, r := range rpcs {
+		words := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(r.comment, isNotWordChar) {
+			words[strings.TrimRight(w, ".")] = true
+		}
+		for i, e := range entries {
+			if words[e.Code] || words[path.Base(e.Package)+"."+e.Var] || (vars[e.Var] == 1 && words[e.Var]) {
+				entries[i].RPCs = append(entries[i].RPCs, r.name)
+			}
+		}
+	}
+}
+
+// isNotWordChar returns true for characters which aren't part of codes, package paths or names
+func isNotWordChar(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_./-", r)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+// registryEntry is a sentinel error in the registry file
+type registryEntry struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Package string `json:"package"`
+	Var     string `json:"var"`
+	Doc     string `json:"doc,omitempty"`
+	Source  string `json:"source"`
+	// RPCs are the proto RPCs which mention the error, see correlateRPCs
+	RPCs []string `json:"rpcs,omitempty"`
+}
+
+// buildRegistry returns an entry for each sentinel in the files, with the RPCs in the
+// proto files which mention it. Sources are relative to the working directory when possible.
+func buildRegistry(files []sourceFile, protoFiles []string) ([]registryEntry, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.Wrap(err, "get working directory")
+	}
+	var entries []registryEntry
+	for _, sf := range files {
+		for _, s := range sentinelsOf(sf) {
+			file := s.file
+			if abs, err := filepath.Abs(file); err == nil {
+				if rel, err := filepath.Rel(wd, abs); err == nil {
+					file = rel
+				}
+			}
+			entries = append(entries, registryEntry{
+				Code:    s.code,
+				Message: s.msg,
+				Package: s.pkg,
+				Var:     s.name,
+				Doc:     s.doc,
+				Source:  fmt.Sprintf("%s:%d", filepath.ToSlash(file), s.line),
+			})
+		}
+	}
+	for _, name := range protoFiles {
+		rpcs, err := parseProtoFile(name)
+		if err != nil {
+			return nil, err
+		}
+		correlateRPCs(entries, rpcs)
+	}
+	return entries, nil
+}
+
+// writeRegistryFile writes the entries as JSON, Markdown or Go, depending on the extension of name.
+// Go files use the name of their directory as the package name.
+func writeRegistryFile(name string, entries []registryEntry) error {
+	var buf bytes.Buffer
+	switch filepath.Ext(name) {
+	case ".json":
+		if err := writeJSONRegistry(&buf, entries); err != nil {
+			return err
+		}
+	case ".md":
+		writeMarkdownRegistry(&buf, entries)
+	case ".go":
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return errors.Wrap(err, "get registry path", j.KV("file", name))
+		}
+		if err := writeGoRegistry(&buf, filepath.Base(filepath.Dir(abs)), entries); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unknown registry file type, expect .json, .md or .go", j.KV("file", name))
+	}
+	return errors.Wrap(os.WriteFile(name, buf.Bytes(), 0o644), "write registry", j.KV("file", name))
+}
+
+func writeJSONRegistry(w io.Writer, entries []registryEntry) error {
+	if entries == nil {
+		entries = []registryEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(entries), "encode registry")
+}
+
+func writeGoRegistry(w io.Writer, pkg string, entries []registryEntry) error {
+	if !token.IsIdentifier(pkg) {
+		return errors.New("invalid package name for registry", j.KV("package", pkg))
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jcode. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "// Error is a sentinel error and its jettison code\n")
+	fmt.Fprintf(&buf, "type Error struct {\nCode string\nMessage string\nPackage string\nVar string\nDoc string\nSource string\nRPCs []string\n}\n\n")
+	fmt.Fprintf(&buf, "// Errors are the sentinel errors, in the order they're declared\n")
+	fmt.Fprintf(&buf, "var Errors = []Error{\n")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "{\nCode: %q,\nMessage: %q,\nPackage: %q,\nVar: %q,\n", e.Code, e.Message, e.Package, e.Var)
+		if e.Doc != "" {
+			fmt.Fprintf(&buf, "Doc: %q,\n", e.Doc)
+		}
+		fmt.Fprintf(&buf, "Source: %q,\n", e.Source)
+		if len(e.RPCs) > 0 {
+			fmt.Fprintf(&buf, "RPCs: %#v,\n", e.RPCs)
+		}
+		fmt.Fprintf(&buf, "},\n")
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "format registry")
+	}
+	_, err = w.Write(b)
+	return errors.Wrap(err, "write registry")
+}
+
+// writeMarkdownRegistry writes a table of the entries, followed by the
+// errors mentioned by each RPC. Sources are left out so that the
+// catalogue only changes when the errors do.
+func writeMarkdownRegistry(w io.Writer, entries []registryEntry) {
+	fmt.Fprintf(w, "# Error codes\n\n")
+	fmt.Fprintf(w, "| Code | Message | Error | Description |\n")
+	fmt.Fprintf(w, "| --- | --- | --- | --- |\n")
+	var rpcs []string
+	byRPC := make(map[string][]registryEntry)
+	for _, e := range entries {
+		fmt.Fprintf(w, "| `%s` | %s | `%s.%s` | %s |\n",
+			e.Code, markdownCell(e.Message), e.Package, e.Var, markdownCell(e.Doc))
+		for _, r := range e.RPCs {
+			if _, ok := byRPC[r]; !ok {
+				rpcs = append(rpcs, r)
+			}
+			byRPC[r] = append(byRPC[r], e)
+		}
+	}
+	if len(rpcs) == 0 {
+		return
+	}
+	slices.Sort(rpcs)
+	fmt.Fprintf(w, "\n## RPCs\n")
+	for _, r := range rpcs {
+		fmt.Fprintf(w, "\n### %s\n\n", r)
+		for _, e := range byRPC[r] {
+			fmt.Fprintf(w, "- `%s` %s\n", e.Code, markdownCell(e.Message))
+		}
+	}
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
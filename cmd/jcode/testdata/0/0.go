@@ -0,0 +1,34 @@
+package testdata
+
+import (
+	"fmt"
+
+	"github.com/luno/jettison/errors"
+)
+
+const c = "ignored constant"
+
+var ErrInvalidCode1 = errors.New("invalid code", errors.C("Not a good code"))
+
+var (
+	// ErrMissingCode has a missing code.
+	ErrMissingCode = errors.New("missing code")
+	// ErrPass1 has a correct code.
+	ErrPass1     = errors.New("pass", errors.WithCode("{code0}"), errors.WithoutStackTrace())
+	ErrPass2     = errors.New("pass", errors.C("{code0}"))
+	ErrTrace     = errors.New("stack trace", errors.WithCode("{code0}"))
+	OtherIgnored = fmt.Errorf("ignored %s", c)
+
+	Err1, Err2 = errors.New("first"), errors.New("second")
+)
+
+// ErrInvalidCode2 has an incorrect code.
+var ErrInvalidCode2 = errors.New("invalid code",
+	errors.C("Not a good code, on a new line"),
+)
+
+func f() {
+	var ErrIgnored = errors.New("ignored errored", errors.WithCode("Not a good code"))
+	ErrAlsoIgnored := errors.New("ignored errored")
+	fmt.Println(ErrIgnored, ErrAlsoIgnored)
+}
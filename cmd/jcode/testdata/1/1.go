@@ -2,4 +2,4 @@ package testdata
 
 import "github.com/luno/jettison/errors"
 
-var errMissingCode = errors.New("missing code")
\ No newline at end of file
+var errMissingCode = errors.New("missing code")
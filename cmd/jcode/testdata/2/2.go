@@ -0,0 +1,11 @@
+package testdata
+
+import (
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+const code = "Not a good code"
+
+// ErrConst has a code which can't be replaced
+var ErrConst = errors.New("const code", j.C(code))
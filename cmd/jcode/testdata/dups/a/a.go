@@ -0,0 +1,12 @@
+package a
+
+import (
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+var (
+	ErrFirst = errors.New("first", j.C("{code0}"))
+	// ErrAlias shares the code in the same package
+	ErrAlias = errors.New("alias", j.C("{code0}"))
+)
@@ -0,0 +1,6 @@
+package b
+
+import "github.com/luno/jettison/errors"
+
+// ErrCopied was copied from package a
+var ErrCopied = errors.New("copied", errors.C("{code0}"))
@@ -0,0 +1,15 @@
+// Command jcodevet runs the jcode analyzers, either directly or with go vet:
+//
+//	jcodevet ./...
+//	go vet -vettool=$(which jcodevet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"github.com/luno/jettison/jcode"
+)
+
+func main() {
+	multichecker.Main(jcode.Analyzers...)
+}
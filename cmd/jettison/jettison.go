@@ -0,0 +1,247 @@
+// Command jettison decodes errors which have been serialised by jettison and
+// writes them as a readable error tree. Each line of the input files, or stdin
+// when no files are given, is either a JSON log entry written by the jettison
+// JSON logger, or a base64 encoded grpc-status-details-bin trailer:
+//
+//	pbpaste | jettison -merged
+//	jettison -code ERR_NOT_FOUND service.log
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/luno/jettison/errors"
+	jetgrpc "github.com/luno/jettison/grpc"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/trace"
+)
+
+const (
+	statusError   = 1
+	statusNoMatch = 2
+
+	detailsHeader = "grpc-status-details-bin:"
+)
+
+var (
+	code       = flag.String("code", "", "only show errors with this code")
+	merged     = flag.Bool("merged", false, "write the merged stack trace of each error, across goroutines and services")
+	stackLines = flag.Int("stack-lines", -1, "collapse the stack trace of each error in the tree to this many lines")
+	noColor    = flag.Bool("no-color", false, "disable colour in the output")
+)
+
+type config struct {
+	code       string
+	merged     bool
+	stackLines int
+	noColor    bool
+}
+
+func main() {
+	flag.Parse()
+	cfg := config{
+		code:       *code,
+		merged:     *merged,
+		stackLines: *stackLines,
+		noColor:    *noColor,
+	}
+
+	var inputs []io.Reader
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%+v\n", err)
+			os.Exit(statusError)
+		}
+		defer f.Close()
+		inputs = append(inputs, f)
+	}
+	if len(inputs) == 0 {
+		inputs = append(inputs, os.Stdin)
+	}
+
+	matched, err := run(cfg, io.MultiReader(inputs...), os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(statusError)
+	}
+	if !matched {
+		os.Exit(statusNoMatch)
+	}
+}
+
+// run writes each entry or status in r to w, it returns false if none matched the config
+func run(cfg config, r io.Reader, w io.Writer) (bool, error) {
+	ol := []log.CmdOption{log.WithCmdErrorTree(true), log.WithCmdStackTraceLines(cfg.stackLines)}
+	if cfg.noColor {
+		ol = append(ol, log.WithCmdColor(false))
+	}
+	l := log.NewCmdLogger(w, false, ol...)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16<<20)
+	var (
+		line    int
+		matched bool
+	)
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		e, err := decode(s)
+		if err != nil {
+			return false, errors.Wrap(err, "decode input", j.KV("line", line))
+		}
+		if cfg.code != "" {
+			filtered, ok := filterCode(e.Err, cfg.code)
+			if !ok {
+				continue
+			}
+			e.Err = filtered
+		}
+		matched = true
+		l.Log(context.Background(), e)
+		if cfg.merged && e.Err != nil {
+			writeMergedTraces(w, e.Err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return false, errors.Wrap(err, "read input")
+	}
+	return matched, nil
+}
+
+// decode returns the log entry of s, which is either a JSON log entry or a status trailer
+func decode(s string) (log.Entry, error) {
+	if strings.HasPrefix(s, "{") {
+		return log.ParseEntry([]byte(s))
+	}
+	return decodeStatus(s)
+}
+
+// decodeStatus decodes a base64 encoded google.rpc.Status,
+// as sent in the grpc-status-details-bin trailer
+func decodeStatus(s string) (log.Entry, error) {
+	if len(s) >= len(detailsHeader) && strings.EqualFold(s[:len(detailsHeader)], detailsHeader) {
+		s = strings.TrimSpace(s[len(detailsHeader):])
+	}
+	b, err := decodeBase64(s)
+	if err != nil {
+		return log.Entry{}, err
+	}
+	var p spb.Status
+	if err := proto.Unmarshal(b, &p); err != nil {
+		return log.Entry{}, errors.Wrap(err, "unmarshal status")
+	}
+	st := status.FromProto(&p)
+	err = jetgrpc.FromError(st.Err())
+	if u := errors.Unwrap(err); u != nil {
+		err = u
+	}
+
+	e := log.Entry{
+		Message: st.Message(),
+		Source:  "grpc status " + st.Code().String(),
+		Level:   log.LevelError,
+	}
+	log.WithError(err).ApplyToLog(&e)
+	return e, nil
+}
+
+// decodeBase64 decodes s with or without padding, gRPC metadata is
+// usually unpadded but the trailer may have been copied from elsewhere
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("input is neither a JSON log entry nor base64", errors.WithoutStackTrace())
+}
+
+// filterCode returns the parts of the error tree which have the code,
+// joined errors without it are left out. It returns false if no errors have the code.
+func filterCode(err error, code string) (error, bool) {
+	switch e := err.(type) {
+	case nil:
+		return nil, false
+	case *internal.Error:
+		if e.Code == code {
+			return e, true
+		}
+		inner, ok := filterCode(e.Err, code)
+		if !ok {
+			return nil, false
+		}
+		cp := *e
+		cp.Err = inner
+		return &cp, true
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, c := range e.Unwrap() {
+			if f, ok := filterCode(c, code); ok {
+				errs = append(errs, f)
+			}
+		}
+		switch len(errs) {
+		case 0:
+			return nil, false
+		case 1:
+			return errs[0], true
+		default:
+			return errors.Join(errs...), true
+		}
+	default:
+		// Other errors can't be copied, keep all of it if any of it has the code
+		_, ok := filterCode(errors.Unwrap(err), code)
+		return err, ok
+	}
+}
+
+// writeMergedTraces writes the stack trace of each path through the error tree,
+// merging the traces from each goroutine and service
+func writeMergedTraces(w io.Writer, err error) {
+	for _, path := range errors.Flatten(err) {
+		var m trace.Merge
+		for _, err := range path {
+			je, ok := err.(*internal.Error)
+			if !ok {
+				continue
+			}
+			if st := je.GetStackTrace(); len(st) > 0 {
+				m.AddSection(trace.Section{
+					Binary:    je.Binary,
+					Trace:     st,
+					Frames:    je.GetFrames(),
+					Goroutine: je.Trace.Goroutine(),
+				})
+			}
+		}
+		full := m.FullTrace()
+		if len(full) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "stack trace of %q:\n", path[len(path)-1].Error())
+		for _, line := range full {
+			_, _ = fmt.Fprintf(w, "  - %s\n", line)
+		}
+	}
+}
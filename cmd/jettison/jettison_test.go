@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/luno/jettison/errors"
+	jetgrpc "github.com/luno/jettison/grpc"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+//go:generate go test . -run TestRun -update
+
+func TestRun(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	err := exampleErr()
+
+	var logs bytes.Buffer
+	log.SetDefaultLoggerForTesting(t, &logs)
+	log.Info(context.Background(), "not an error")
+	log.Error(context.Background(), err)
+
+	testCases := []struct {
+		name string
+		cfg  config
+		in   string
+	}{
+		{name: "log_entries", cfg: config{stackLines: -1}, in: logs.String()},
+		{name: "status", cfg: config{stackLines: -1}, in: encodeStatus(t, err, base64.RawStdEncoding)},
+		{name: "status_header", cfg: config{stackLines: -1}, in: detailsHeader + " " + encodeStatus(t, err, base64.StdEncoding)},
+		{name: "filter_code", cfg: config{code: "ERR_TWO", stackLines: -1}, in: encodeStatus(t, err, base64.StdEncoding)},
+		{name: "merged", cfg: config{merged: true}, in: encodeStatus(t, err, base64.StdEncoding)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			matched, err := run(tc.cfg, strings.NewReader(tc.in), &out)
+			require.NoError(t, err)
+			assert.True(t, matched)
+			goldie.New(t).Assert(t, tc.name, out.Bytes())
+		})
+	}
+}
+
+func TestRunNoMatch(t *testing.T) {
+	in := encodeStatus(t, exampleErr(), base64.StdEncoding)
+	var out bytes.Buffer
+	matched, err := run(config{code: "ERR_MISSING"}, strings.NewReader(in), &out)
+	require.NoError(t, err)
+	assert.False(t, matched)
+	assert.Empty(t, out.String())
+}
+
+func TestRunInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+	}{
+		{name: "json", in: `{"message":`},
+		{name: "base64", in: "not base64!"},
+		{name: "proto", in: base64.StdEncoding.EncodeToString([]byte("not a status"))},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := run(config{}, strings.NewReader(tc.in), io.Discard)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func exampleErr() error {
+	err := errors.Join(
+		errors.New("one", errors.WithCode("ERR_ONE")),
+		errors.New("two", errors.WithCode("ERR_TWO"), j.KV("key", "value")),
+	)
+	return errors.Wrap(err, "do things")
+}
+
+func encodeStatus(t *testing.T, err error, enc *base64.Encoding) string {
+	b, err := proto.Marshal(jetgrpc.Wrap(err).GRPCStatus().Proto())
+	require.NoError(t, err)
+	return enc.EncodeToString(b)
+}
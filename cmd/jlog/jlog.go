@@ -0,0 +1,340 @@
+// Command jlog reads logs written by the jettison JSON logger from files,
+// or stdin when no files are given, and writes the entries which match
+// the filters with the command line logger's error tree:
+//
+//	jlog -level error -where account_id=123 service.log
+//	jlog -f -code ERR_NOT_FOUND -source github.com/luno/service/lookup service.log
+//
+// Lines which aren't JSON log entries are written unchanged, unless a filter is used.
+//
+// The JSON logs only record the flattened path to each error, so the tree shows
+// where errors were joined but each path is written as a single error, with the
+// message, code and key/values of the whole path rather than each wrap.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+const statusError = 1
+
+// pollInterval is how often followed files are checked for new lines
+var pollInterval = 250 * time.Millisecond
+
+var levels = map[log.Level]int{
+	log.LevelDebug: 0,
+	log.LevelInfo:  1,
+	log.LevelError: 2,
+}
+
+type whereFlags []string
+
+func (w *whereFlags) String() string {
+	return strings.Join(*w, ",")
+}
+
+func (w *whereFlags) Set(s string) error {
+	*w = append(*w, s)
+	return nil
+}
+
+type filter struct {
+	level  log.Level
+	source string
+	code   string
+	where  []string
+}
+
+// empty returns true if the filter matches every entry
+func (f filter) empty() bool {
+	return f.level == "" && f.source == "" && f.code == "" && len(f.where) == 0
+}
+
+func (f filter) validate() error {
+	if _, ok := levels[f.level]; f.level != "" && !ok {
+		return errors.New("unknown level", j.KV("level", f.level))
+	}
+	return nil
+}
+
+func (f filter) matches(e log.Entry) bool {
+	if f.level != "" && levels[e.Level] < levels[f.level] {
+		return false
+	}
+	if f.source != "" && !matchPackage(e.Source, f.source) {
+		return false
+	}
+	if f.code != "" && !hasCode(e, f.code) {
+		return false
+	}
+	for _, w := range f.where {
+		if !hasParameter(e, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPackage returns true if the source is in the package pkg,
+// pkg may be the full import path or its last elements
+func matchPackage(source, pkg string) bool {
+	dir := path.Dir(source)
+	return dir == pkg || strings.HasSuffix(dir, "/"+pkg)
+}
+
+func hasCode(e log.Entry, code string) bool {
+	if e.ErrorCode != nil && *e.ErrorCode == code {
+		return true
+	}
+	if e.ErrorObject != nil && e.ErrorObject.Code == code {
+		return true
+	}
+	for _, eo := range e.ErrorObjects {
+		if eo.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// hasParameter returns true if the entry has a parameter matching w,
+// which is either key=value or a key which has any value
+func hasParameter(e log.Entry, w string) bool {
+	key, value, hasValue := strings.Cut(w, "=")
+	for _, kv := range e.Parameters {
+		if kv.Key == key && (!hasValue || kv.Value == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// printer writes the lines which match the filter, it's safe for concurrent use
+type printer struct {
+	filter filter
+	logger *log.CmdLogger
+	w      io.Writer
+
+	mu sync.Mutex
+}
+
+func newPrinter(f filter, w io.Writer, ol ...log.CmdOption) *printer {
+	ol = append([]log.CmdOption{log.WithCmdErrorTree(true)}, ol...)
+	return &printer{filter: f, logger: log.NewCmdLogger(w, false, ol...), w: w}
+}
+
+func (p *printer) line(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, err := log.ParseEntry(b)
+	if err != nil {
+		if p.filter.empty() {
+			_, _ = fmt.Fprintf(p.w, "%s\n", b)
+		}
+		return
+	}
+	if p.filter.matches(e) {
+		p.logger.Log(context.Background(), e)
+	}
+}
+
+// read passes each line of r to the printer
+func (p *printer) read(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16<<20)
+	for sc.Scan() {
+		if len(sc.Bytes()) > 0 {
+			p.line(sc.Bytes())
+		}
+	}
+	return errors.Wrap(sc.Err(), "read logs")
+}
+
+// follow passes each line of the file to the printer, then waits for more lines
+// to be written until ctx is cancelled. When the file is truncated it's read from
+// the start and when it's replaced, e.g. after being rotated, the new file is read.
+func (p *printer) follow(ctx context.Context, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return errors.Wrap(err, "open log file", j.KV("path", name))
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	var partial []byte
+	for {
+		if err := p.readLines(r, &partial); err != nil {
+			return errors.Wrap(err, "read log file", j.KV("path", name))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+
+		next, truncated, err := reopen(f, name)
+		if err != nil {
+			return err
+		}
+		if truncated {
+			// Anything left of the old content is gone, start again from the new content
+			r.Reset(f)
+			partial = partial[:0]
+		} else if next != f {
+			// Finish the lines written before the file was replaced
+			if err := p.readLines(r, &partial); err != nil {
+				return errors.Wrap(err, "read log file", j.KV("path", name))
+			}
+			_ = f.Close()
+			f = next
+			r.Reset(f)
+			partial = partial[:0]
+		}
+	}
+}
+
+// readLines passes each complete line in r to the printer until the end of r,
+// an incomplete line at the end is kept in partial until the rest is written
+func (p *printer) readLines(r *bufio.Reader, partial *[]byte) error {
+	for {
+		b, err := r.ReadBytes('\n')
+		*partial = append(*partial, b...)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if line := bytes.TrimSpace(*partial); len(line) > 0 {
+			p.line(line)
+		}
+		*partial = (*partial)[:0]
+	}
+}
+
+// reopen returns the file to continue reading from, which is f unless the
+// file at name has been replaced. When f has been truncated it's read from
+// the start and reopen returns true.
+func reopen(f *os.File, name string) (*os.File, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, errors.Wrap(err, "stat log file", j.KV("path", name))
+	}
+	latest, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		// The file has been moved and not yet replaced
+		return f, false, nil
+	} else if err != nil {
+		return nil, false, errors.Wrap(err, "stat log file", j.KV("path", name))
+	}
+	if !os.SameFile(info, latest) {
+		next, err := os.Open(name)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "open log file", j.KV("path", name))
+		}
+		return next, false, nil
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "seek log file", j.KV("path", name))
+	}
+	if info.Size() >= offset {
+		return f, false, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, false, errors.Wrap(err, "seek log file", j.KV("path", name))
+	}
+	return f, true, nil
+}
+
+func main() {
+	var (
+		f       filter
+		level   string
+		follow  bool
+		noColor bool
+	)
+	flag.StringVar(&level, "level", "", "only show entries with this level or above, one of debug, info or error")
+	flag.StringVar(&f.source, "source", "", "only show entries logged in this package, e.g. github.com/luno/jettison/log or log")
+	flag.StringVar(&f.code, "code", "", "only show entries with errors with this code")
+	flag.Var((*whereFlags)(&f.where), "where", "only show entries with this parameter, key=value or key, may be repeated")
+	flag.BoolVar(&follow, "f", false, "follow the files, writing entries as they're logged")
+	flag.BoolVar(&noColor, "no-color", false, "disable colour in the output")
+	flag.Parse()
+
+	f.level = log.Level(level)
+	if err := f.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		flag.Usage()
+		os.Exit(statusError)
+	}
+	var ol []log.CmdOption
+	if noColor {
+		ol = append(ol, log.WithCmdColor(false))
+	}
+	p := newPrinter(f, os.Stdout, ol...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, p, flag.Args(), follow); err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(statusError)
+	}
+}
+
+func run(ctx context.Context, p *printer, files []string, follow bool) error {
+	if len(files) == 0 {
+		return p.read(os.Stdin)
+	}
+	if !follow {
+		for _, name := range files {
+			if err := readFile(p, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Stop following all the files if any of them fail
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(files))
+	for i, name := range files {
+		wg.Go(func() {
+			errs[i] = p.follow(ctx, name)
+			if errs[i] != nil {
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func readFile(p *printer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return errors.Wrap(err, "open log file", j.KV("path", name))
+	}
+	defer f.Close()
+	return p.read(f)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+//go:generate go test . -run TestRead -update
+
+func TestRead(t *testing.T) {
+	logs := exampleLogs(t)
+
+	testCases := []struct {
+		name   string
+		filter filter
+	}{
+		{name: "all"},
+		{name: "level", filter: filter{level: log.LevelError}},
+		{name: "source", filter: filter{source: "jlog"}},
+		{name: "code", filter: filter{code: "ERR_TWO"}},
+		{name: "where", filter: filter{where: []string{"account_id=123"}}},
+		{name: "where_key", filter: filter{where: []string{"account_id"}}},
+		{name: "where_all", filter: filter{where: []string{"account_id=123", "user=alice"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := newPrinter(tc.filter, &out, log.WithCmdColor(false))
+			require.NoError(t, p.read(strings.NewReader(logs)))
+			goldie.New(t).Assert(t, tc.name, out.Bytes())
+		})
+	}
+}
+
+func exampleLogs(t *testing.T) string {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	var buf bytes.Buffer
+	log.SetDefaultLoggerForTesting(t, &buf)
+
+	ctx := context.Background()
+	log.Debug(ctx, "starting")
+	log.Info(ctx, "lookup account", j.KV("account_id", 123), j.KV("user", "alice"))
+	log.Info(ctx, "lookup account", j.KV("account_id", 456))
+	buf.WriteString("not a log entry\n")
+	log.Error(ctx, errors.New("not found", errors.WithCode("ERR_ONE")), j.KV("account_id", 123))
+	log.Error(ctx, errors.Join(
+		errors.New("one", errors.WithCode("ERR_ONE")),
+		errors.New("two", errors.WithCode("ERR_TWO")),
+	))
+	return buf.String()
+}
+
+func TestMatchPackage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		pkg    string
+		exp    bool
+	}{
+		{name: "full path", source: "github.com/luno/jettison/log/log.go:12", pkg: "github.com/luno/jettison/log", exp: true},
+		{name: "last element", source: "github.com/luno/jettison/log/log.go:12", pkg: "log", exp: true},
+		{name: "last elements", source: "github.com/luno/jettison/log/log.go:12", pkg: "jettison/log", exp: true},
+		{name: "parent", source: "github.com/luno/jettison/log/log.go:12", pkg: "jettison", exp: false},
+		{name: "partial element", source: "github.com/luno/jettison/log/log.go:12", pkg: "og", exp: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, matchPackage(tc.source, tc.pkg))
+		})
+	}
+}
+
+// syncBuffer allows the output to be checked while files are followed
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFollow(t *testing.T) {
+	pollInterval = time.Millisecond
+	t.Cleanup(func() { pollInterval = 250 * time.Millisecond })
+
+	name := filepath.Join(t.TempDir(), "service.log")
+	require.NoError(t, os.WriteFile(name, []byte("one\n"), 0o644))
+
+	var out syncBuffer
+	p := newPrinter(filter{}, &out, log.WithCmdColor(false))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- run(ctx, p, []string{name}, true)
+	}()
+
+	expect := func(s string) {
+		assert.Eventually(t, func() bool {
+			return strings.HasSuffix(out.String(), s)
+		}, time.Second, time.Millisecond, "expected %q in %q", s, out.String())
+	}
+	expect("one\n")
+
+	appendFile(t, name, "tw")
+	time.Sleep(10 * time.Millisecond)
+	appendFile(t, name, "o\n")
+	expect("one\ntwo\n")
+
+	// Rotated
+	require.NoError(t, os.Rename(name, name+".1"))
+	require.NoError(t, os.WriteFile(name, []byte("three\n"), 0o644))
+	expect("two\nthree\n")
+
+	// Truncated
+	require.NoError(t, os.WriteFile(name, []byte("four\n"), 0o644))
+	expect("three\nfour\n")
+
+	// Truncated while a line was partly written
+	appendFile(t, name, "fi")
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(name, []byte("six\n"), 0o644))
+	expect("four\nsix\n")
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func appendFile(t *testing.T, name, s string) {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(s)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
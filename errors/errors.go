@@ -4,6 +4,7 @@ import (
 	stderrors "errors"
 
 	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/trace"
 )
 
 type ErrorOption func(je *internal.Error)
@@ -17,7 +18,8 @@ func WithStackTrace() Option {
 	bin, tr := getTrace(1)
 	return ErrorOption(func(je *internal.Error) {
 		je.Binary = bin
-		je.StackTrace = tr
+		je.StackTrace = nil
+		je.Trace = tr
 	})
 }
 
@@ -47,6 +49,7 @@ func WithoutStackTrace() Option {
 	return ErrorOption(func(je *internal.Error) {
 		je.Binary = ""
 		je.StackTrace = nil
+		je.Trace = nil
 	})
 }
 
@@ -65,11 +68,15 @@ type Option interface {
 
 // New creates a new JettisonError with a populated stack trace
 func New(msg string, ol ...Option) error {
+	return newError(1, msg, ol)
+}
+
+func newError(skip int, msg string, ol []Option) error {
 	je := &internal.Error{
 		Message: msg,
-		Source:  getSourceCode(1),
+		Source:  getSourceCode(skip + 1),
 	}
-	je.Binary, je.StackTrace = getTrace(1)
+	je.Binary, je.Trace = getTrace(skip + 1)
 	for _, o := range ol {
 		o.ApplyToError(je)
 	}
@@ -78,19 +85,25 @@ func New(msg string, ol ...Option) error {
 
 // Wrap will wrap an existing error in a new JettisonError.
 // If no error in the err error tree has a trace, a stack trace is populated.
+// When StackConfig.TrackGoroutines is set, a stack trace is also populated
+// if the latest trace in the tree was captured on a different goroutine.
 func Wrap(err error, msg string, ol ...Option) error {
+	return wrap(1, err, msg, ol)
+}
+
+func wrap(skip int, err error, msg string, ol []Option) error {
 	if err == nil {
 		return nil
 	}
 	je := &internal.Error{
 		Message: msg,
 		Err:     err,
-		Source:  getSourceCode(1),
+		Source:  getSourceCode(skip + 1),
 	}
 	// We only need to add a trace when wrapping sentinel or non-jettison errors
-	// for the first time
-	if _, _, found := GetLastStackTrace(err); !found {
-		je.Binary, je.StackTrace = getTrace(1)
+	// for the first time, or when the error has come from another goroutine
+	if needsStackTrace(err) {
+		je.Binary, je.Trace = getTrace(skip + 1)
 	}
 	for _, o := range ol {
 		o.ApplyToError(je)
@@ -160,13 +173,36 @@ func GetLastStackTrace(err error) (string, []string, bool) {
 			return true
 		}
 		bin = je.Binary
-		stack = je.StackTrace
+		stack = je.GetStackTrace()
 		found = true
 		return false
 	})
 	return bin, stack, found
 }
 
+// needsStackTrace returns true if no error in the tree has a stack trace or,
+// when tracking goroutines, the latest stack trace is from another goroutine.
+// It avoids formatting any stack traces.
+func needsStackTrace(err error) bool {
+	var last *internal.Error
+	Walk(err, func(err error) bool {
+		je, ok := err.(*internal.Error)
+		if !ok || je.Binary == "" {
+			return true
+		}
+		last = je
+		return false
+	})
+	if last == nil {
+		return true
+	}
+	if !traceConfig.TrackGoroutines {
+		return false
+	}
+	g := last.Trace.Goroutine()
+	return g != 0 && g != trace.CurrentGoroutine()
+}
+
 // GetKeyValues returns all embedded key value info in the error
 func GetKeyValues(err error) map[string]string {
 	ret := make(map[string]string)
@@ -13,6 +13,7 @@ import (
 	"github.com/luno/jettison/internal"
 	"github.com/luno/jettison/j"
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
 func TestNew(t *testing.T) {
@@ -382,11 +383,11 @@ func TestIsUnwrap(t *testing.T) {
 func TestWithoutStackTrace(t *testing.T) {
 	errFoo := errors.New("foo", errors.WithoutStackTrace()).(*internal.Error)
 	assert.Empty(t, errFoo.Binary)
-	assert.Empty(t, errFoo.StackTrace)
+	assert.Empty(t, errFoo.GetStackTrace())
 
 	err := errors.Wrap(errFoo, "wrap adds stack trace").(*internal.Error)
 	assert.NotEmpty(t, err.Binary)
-	assert.NotEmpty(t, err.StackTrace)
+	assert.NotEmpty(t, err.GetStackTrace())
 }
 
 func TestErrorMetadata(t *testing.T) {
@@ -449,10 +450,10 @@ func TestErrorMetadata(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.expNoTrace {
 				assert.Empty(t, tc.err.Binary)
-				assert.Empty(t, tc.err.StackTrace)
+				assert.Empty(t, tc.err.GetStackTrace())
 			} else {
 				assert.NotEmpty(t, tc.err.Binary)
-				assert.NotEmpty(t, tc.err.StackTrace)
+				assert.NotEmpty(t, tc.err.GetStackTrace())
 			}
 
 			assert.Equal(t, tc.expError.Message, tc.err.Message)
@@ -464,15 +465,15 @@ func TestErrorMetadata(t *testing.T) {
 
 func TestWithStacktrace(t *testing.T) {
 	base := errors.New("base").(*internal.Error)
-	assert.NotEmpty(t, base.StackTrace)
+	assert.NotEmpty(t, base.GetStackTrace())
 
 	// No stack trace if base error has one already
 	wrapped := errors.Wrap(base, "wrap").(*internal.Error)
-	assert.Empty(t, wrapped.StackTrace)
+	assert.Empty(t, wrapped.GetStackTrace())
 
 	// Get trace if explicitly requested
 	wst := errors.Wrap(base, "stacky", errors.WithStackTrace()).(*internal.Error)
-	assert.NotEmpty(t, wst.StackTrace)
+	assert.NotEmpty(t, wst.GetStackTrace())
 }
 
 func TestWalk(t *testing.T) {
@@ -623,3 +624,55 @@ func TestGetLastStackTrace(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = errors.New("not found")
+	}
+}
+
+func BenchmarkNewFormatted(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := errors.New("not found")
+		_ = err.(*internal.Error).GetStackTrace()
+	}
+}
+
+func TestWrapAcrossGoroutines(t *testing.T) {
+	errCh := make(chan error)
+	produce := func() {
+		go func() { errCh <- errors.New("from worker") }()
+	}
+
+	t.Run("not tracked", func(t *testing.T) {
+		cfg := errors.TestingConfig
+		errors.SetTraceConfigTesting(t, cfg)
+		produce()
+		err := errors.Wrap(<-errCh, "consumed").(*internal.Error)
+		assert.Empty(t, err.Binary)
+		assert.Nil(t, err.Trace)
+	})
+
+	t.Run("tracked", func(t *testing.T) {
+		cfg := errors.TestingConfig
+		cfg.TrackGoroutines = true
+		errors.SetTraceConfigTesting(t, cfg)
+		produce()
+		err := errors.Wrap(<-errCh, "consumed").(*internal.Error)
+		assert.NotEmpty(t, err.Binary)
+		assert.NotZero(t, err.Trace.Goroutine())
+		assert.NotEqual(t, err.Err.(*internal.Error).Trace.Goroutine(), err.Trace.Goroutine())
+
+		// Wrapping again on the same goroutine doesn't add another trace
+		again := errors.Wrap(err, "again").(*internal.Error)
+		assert.Empty(t, again.Binary)
+	})
+}
+
+func TestProcessIdentity(t *testing.T) {
+	trace.SetIdentityForTesting(t, trace.Identity{Service: "svc", Version: "v1.2.3", Host: "svc-1"})
+	err := errors.New("identified").(*internal.Error)
+	assert.Equal(t, "svc@v1.2.3 (svc-1)", err.Binary)
+}
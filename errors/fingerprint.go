@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/luno/jettison/internal"
+)
+
+type fingerprintConfig struct {
+	ignoreLines bool
+}
+
+type FingerprintOption func(*fingerprintConfig)
+
+// IgnoreLineNumbers leaves line numbers out of the fingerprint,
+// so that errors are still grouped together when unrelated lines
+// are added or removed from the source code.
+func IgnoreLineNumbers() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.ignoreLines = true
+	}
+}
+
+// Fingerprint returns a hash which can be used to group occurrences of the same error.
+// The hash is made from the codes of the errors in the tree and their stack traces.
+// Jettison errors without a code use their message instead, other errors use their type.
+// The binaries where the traces were captured aren't included, so errors which
+// cross between services are grouped regardless of which instance they came from.
+func Fingerprint(err error, ol ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+	paths := Flatten(err)
+	if len(paths) == 1 {
+		return FingerprintPath(paths[0], ol...)
+	}
+	h := sha256.New()
+	for _, p := range paths {
+		_, _ = io.WriteString(h, FingerprintPath(p, ol...))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// FingerprintPath returns the Fingerprint of a single path through an error tree,
+// see Flatten for details of how the paths are created.
+func FingerprintPath(path []error, ol ...FingerprintOption) string {
+	var c fingerprintConfig
+	for _, o := range ol {
+		o(&c)
+	}
+	h := sha256.New()
+	for _, err := range path {
+		if _, isJoin := err.(interface{ Unwrap() []error }); isJoin {
+			continue
+		}
+		je, ok := err.(*internal.Error)
+		if !ok {
+			_, _ = fmt.Fprintf(h, "type:%T\n", err)
+			continue
+		}
+		if je.Code != "" {
+			_, _ = fmt.Fprintf(h, "code:%s\n", je.Code)
+		} else if je.Message != "" {
+			_, _ = fmt.Fprintf(h, "msg:%s\n", je.Message)
+		}
+		writeTrace(h, je, c)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// lineNumbers matches line numbers in formatted stack traces,
+// e.g. "file.go:123" or links like "file.go#L123"
+var lineNumbers = regexp.MustCompile(`(:|#L)\d+`)
+
+func writeTrace(w io.Writer, je *internal.Error, c fingerprintConfig) {
+	if frames := je.GetFrames(); len(frames) > 0 {
+		for _, f := range frames {
+			line := ""
+			if !c.ignoreLines {
+				line = ":" + strconv.Itoa(f.Line)
+			}
+			_, _ = fmt.Fprintf(w, "frame:%s/%s%s\n", f.Package, f.Function, line)
+		}
+		return
+	}
+	lines := je.StackTrace
+	if je.Trace != nil {
+		// Links change with every release, so they're left out
+		lines = je.Trace.UnlinkedLines()
+	}
+	for _, l := range lines {
+		if c.ignoreLines {
+			l = lineNumbers.ReplaceAllString(l, "")
+		}
+		_, _ = fmt.Fprintf(w, "frame:%s\n", l)
+	}
+}
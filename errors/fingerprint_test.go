@@ -0,0 +1,131 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/go-stack/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/trace"
+)
+
+func newAt(code string) error {
+	return errors.New("failed", errors.WithCode(code))
+}
+
+func wrapAt(err error) error {
+	return errors.Wrap(err, "wrapped")
+}
+
+func TestFingerprint(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	assert.Equal(t, "", errors.Fingerprint(nil))
+
+	fp := errors.Fingerprint(newAt("ERR_1"))
+	assert.Len(t, fp, 32)
+
+	testCases := []struct {
+		name  string
+		err   error
+		equal bool
+	}{
+		{name: "same error", err: newAt("ERR_1"), equal: true},
+		{name: "different code", err: newAt("ERR_2")},
+		{name: "different stack", err: errors.New("failed", errors.WithCode("ERR_1"))},
+		{name: "wrapped", err: wrapAt(newAt("ERR_1"))},
+		{name: "joined", err: errors.Join(newAt("ERR_1"), io.EOF)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.equal {
+				assert.Equal(t, fp, errors.Fingerprint(tc.err))
+			} else {
+				assert.NotEqual(t, fp, errors.Fingerprint(tc.err))
+			}
+		})
+	}
+}
+
+func TestFingerprintIgnoresBinary(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	err1 := newAt("ERR_1")
+	err2 := newAt("ERR_1")
+	err2.(*internal.Error).Binary = "another-service@v1.2.3 (host-2)"
+
+	assert.Equal(t, errors.Fingerprint(err1), errors.Fingerprint(err2))
+}
+
+func TestFingerprintNonJettison(t *testing.T) {
+	assert.Equal(t, errors.Fingerprint(io.EOF), errors.Fingerprint(io.ErrUnexpectedEOF))
+	assert.NotEqual(t, errors.Fingerprint(io.EOF), errors.Fingerprint(fmt.Errorf("wrapped: %w", io.EOF)))
+}
+
+func TestFingerprintLineNumbers(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config trace.StackConfig
+	}{
+		{
+			name: "stack lines",
+			config: trace.StackConfig{
+				TrimRuntime: true,
+				FormatStack: func(call stack.Call) string { return fmt.Sprintf("%+v", call) },
+			},
+		},
+		{
+			name:   "frames",
+			config: trace.StackConfig{TrimRuntime: true, IncludeFrames: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errors.SetTraceConfigTesting(t, tc.config)
+
+			var errs []error
+			for range 2 {
+				errs = append(errs, errors.New("failed", errors.WithCode("ERR_1")))
+			}
+			errs = append(errs, errors.New("failed", errors.WithCode("ERR_1")))
+
+			assert.Equal(t, errors.Fingerprint(errs[0]), errors.Fingerprint(errs[1]))
+			assert.NotEqual(t, errors.Fingerprint(errs[0]), errors.Fingerprint(errs[2]))
+			assert.Equal(t,
+				errors.Fingerprint(errs[0], errors.IgnoreLineNumbers()),
+				errors.Fingerprint(errs[2], errors.IgnoreLineNumbers()),
+			)
+		})
+	}
+}
+
+func TestFingerprintIgnoresLinks(t *testing.T) {
+	errors.SetTraceConfigTesting(t, trace.StackConfig{
+		TrimRuntime:  true,
+		LinkTemplate: "https://{module}/blob/{ref}/{path}#L{line}",
+	})
+
+	var (
+		errs []error
+		fps  []string
+	)
+	for _, rev := range []string{"7835f813f4da", "1c2b3e4f5a6b"} {
+		trace.SetRevisionForTesting(t, rev)
+		err := newAt("ERR_1")
+		errs = append(errs, err)
+		fps = append(fps, errors.Fingerprint(err, errors.IgnoreLineNumbers()))
+	}
+	// Check that the stack traces do have links which differ
+	st0, st1 := errs[0].(*internal.Error).GetStackTrace(), errs[1].(*internal.Error).GetStackTrace()
+	require.NotEmpty(t, st0)
+	assert.Contains(t, st0[0], "/blob/7835f813f4da/")
+	assert.Contains(t, st1[0], "/blob/1c2b3e4f5a6b/")
+
+	assert.Equal(t, fps[0], fps[1])
+	assert.Equal(t, errors.Fingerprint(errs[0]), errors.Fingerprint(errs[1]))
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/luno/jettison/internal"
+)
+
+// Group runs tasks in their own goroutines and collects every error they return.
+// Unlike errgroup.Group, no errors are discarded, Wait returns all the task
+// errors joined together.
+//
+// Each task error is wrapped with the stack trace of the call to Go,
+// as well as any options given to Go, which can be used to identify the task.
+//
+//	g, ctx := errors.NewGroup(ctx)
+//	for _, id := range ids {
+//	  g.Go(func() error {
+//	    return process(ctx, id)
+//	  }, j.KV("id", id))
+//	}
+//	return g.Wait()
+//
+// The zero value is a valid Group with no concurrency limit and no context.
+type Group struct {
+	cancel context.CancelCauseFunc
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a new Group and a derived context.
+// The context is cancelled the first time a task returns an error or
+// when Wait returns, whichever happens first.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of tasks running concurrently to n,
+// a negative n removes the limit.
+// The limit must not be changed while any tasks are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic("errors: modify limit while tasks are running")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls f in a new goroutine, blocking until there is capacity
+// if a limit has been set.
+// If f returns an error or panics, the error is wrapped with ol
+// and a stack trace of the call to Go.
+func (g *Group) Go(f func() error, ol ...Option) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	src := getSourceCode(1)
+	bin, tr := getTrace(1)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+
+		err := runTask(f)
+		if err == nil {
+			return
+		}
+		je := &internal.Error{
+			Err:    err,
+			Source: src,
+			Binary: bin,
+			Trace:  tr,
+		}
+		for _, o := range ol {
+			o.ApplyToError(je)
+		}
+		g.addError(je)
+	}()
+}
+
+// Wait blocks until all tasks have completed, returning all errors
+// from the tasks joined together or nil if no tasks failed.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Join(g.errs...)
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+func (g *Group) addError(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 && g.cancel != nil {
+		g.cancel(err)
+	}
+	g.errs = append(g.errs, err)
+}
+
+func runTask(f func() error) (err error) {
+	defer Recover(&err)
+	return f()
+}
@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/j"
+)
+
+func TestGroup(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	errOdd := errors.New("odd", j.C("ERR_odd"))
+
+	g, ctx := errors.NewGroup(context.Background())
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			if i%2 == 1 {
+				return errors.Wrap(errOdd, "task failed")
+			}
+			return nil
+		}, j.KV("task", i))
+	}
+	err := g.Wait()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errOdd))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.True(t, errors.Is(context.Cause(ctx), errOdd))
+
+	paths := errors.Flatten(err)
+	require.Len(t, paths, 5)
+
+	var tasks []string
+	for _, p := range paths {
+		je := p[1].(*internal.Error)
+		assert.Equal(t, "group_test.go TestGroup", je.Source)
+		assert.Equal(t, []string{"group_test.go TestGroup"}, je.GetStackTrace())
+		require.Len(t, je.KV, 1)
+		tasks = append(tasks, je.KV[0].Value)
+	}
+	assert.ElementsMatch(t, []string{"1", "3", "5", "7", "9"}, tasks)
+}
+
+func TestGroupNoErrors(t *testing.T) {
+	g, ctx := errors.NewGroup(context.Background())
+	for i := 0; i < 10; i++ {
+		g.Go(func() error { return nil })
+	}
+	assert.NoError(t, g.Wait())
+	assert.Equal(t, context.Canceled, context.Cause(ctx))
+}
+
+func TestGroupZeroValue(t *testing.T) {
+	var g errors.Group
+	g.Go(func() error { return io.EOF }, j.KV("task", "eof"))
+	g.Go(func() error { panic("oops") }, j.KV("task", "panic"))
+
+	err := g.Wait()
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, errors.Is(err, errors.ErrPanic))
+	assert.Len(t, errors.Flatten(err), 2)
+}
+
+func TestGroupLimit(t *testing.T) {
+	const limit = 3
+	var g errors.Group
+	g.SetLimit(limit)
+
+	var running, maxRunning atomic.Int64
+	for i := 0; i < 20; i++ {
+		g.Go(func() error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return errors.New("failed", j.KS("task", strconv.Itoa(i)))
+		})
+	}
+	err := g.Wait()
+	assert.Len(t, errors.Flatten(err), 20)
+	assert.LessOrEqual(t, maxRunning.Load(), int64(limit))
+}
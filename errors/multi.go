@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/models"
+)
+
+// FieldKey is the key used by Field to identify the field an error relates to
+const FieldKey = "field"
+
+// Field is an option which identifies the field that an error relates to.
+// It is typically used with Multi when validating requests.
+func Field(name string) Option {
+	return ErrorOption(func(je *internal.Error) {
+		je.KV = append(je.KV, models.KeyValue{Key: FieldKey, Value: name})
+	})
+}
+
+// Multi accumulates errors so that they can be returned together,
+// this is useful for validation, where all the problems should be reported
+// rather than just the first one found.
+//
+//	var m errors.Multi
+//	m.AddIf(req.Name == "", "name is required", errors.Field("name"))
+//	m.AddIf(req.Amount < 0, "amount must be positive", errors.Field("amount"))
+//	m.Add(validateAddress(req.Address), errors.Field("address"))
+//	return m.ErrOrNil()
+//
+// The zero value is ready to use.
+type Multi struct {
+	errs []error
+}
+
+// Add adds err to the accumulated errors, nil errors are ignored.
+// When options are provided, err is wrapped with them.
+func (m *Multi) Add(err error, ol ...Option) {
+	if err == nil {
+		return
+	}
+	if len(ol) > 0 {
+		err = wrap(1, err, "", ol)
+	}
+	m.errs = append(m.errs, err)
+}
+
+// AddIf adds a new error with the message msg and options ol if cond is true
+func (m *Multi) AddIf(cond bool, msg string, ol ...Option) {
+	if !cond {
+		return
+	}
+	m.errs = append(m.errs, newError(1, msg, ol))
+}
+
+// Len returns the number of errors added
+func (m *Multi) Len() int {
+	return len(m.errs)
+}
+
+// ErrOrNil returns all the added errors joined together, or nil if there are none
+func (m *Multi) ErrOrNil() error {
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return Join(m.errs...)
+}
@@ -0,0 +1,49 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/j"
+)
+
+func TestMulti(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	var m errors.Multi
+	assert.NoError(t, m.ErrOrNil())
+
+	m.Add(nil, errors.Field("nil"))
+	m.AddIf(false, "not added")
+	assert.Equal(t, 0, m.Len())
+	assert.NoError(t, m.ErrOrNil())
+
+	m.AddIf(true, "name is required", errors.Field("name"))
+	m.Add(io.ErrUnexpectedEOF, errors.Field("body"), j.KV("size", 10))
+	m.Add(io.EOF)
+	require.Equal(t, 3, m.Len())
+
+	err := m.ErrOrNil()
+	assert.Equal(t, "name is required\nunexpected EOF\nEOF", err.Error())
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.Is(err, io.EOF))
+
+	paths := errors.Flatten(err)
+	require.Len(t, paths, 3)
+
+	name := paths[0][1].(*internal.Error)
+	assert.Equal(t, "multi_test.go TestMulti", name.Source)
+	assert.Equal(t, []string{"multi_test.go TestMulti"}, name.GetStackTrace())
+	assert.Equal(t, map[string]string{"field": "name"}, errors.GetKeyValues(name))
+
+	body := paths[1][1].(*internal.Error)
+	assert.Equal(t, "multi_test.go TestMulti", body.Source)
+	assert.Equal(t, map[string]string{"field": "body", "size": "10"}, errors.GetKeyValues(body))
+
+	assert.Equal(t, io.EOF, paths[2][1])
+}
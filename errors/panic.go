@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/models"
+)
+
+// ErrPanic is the code given to errors which were created from a recovered panic.
+// Use Is(err, ErrPanic) to check if an error was caused by a panic.
+var ErrPanic = New("panic", C("ERR_6c0ba8a4d5f3e217"))
+
+// FromPanic converts a value returned by recover() into an error.
+// The stack trace and source are taken from where the panic occurred
+// and the panic value is added as the "panic" key value.
+// If the panic value is an error, it is wrapped by the returned error.
+// A nil value returns a nil error.
+//
+//	defer func() {
+//	  if err := errors.FromPanic(recover()); err != nil {
+//	    log.Error(ctx, err)
+//	  }
+//	}()
+func FromPanic(r any) error {
+	return fromPanic(r, 1)
+}
+
+// Recover will recover from a panic and set err to an error created using FromPanic.
+// It must be deferred directly, otherwise the panic won't be recovered.
+// If there's no panic, then err is left unchanged.
+//
+//	func work() (err error) {
+//	  defer errors.Recover(&err)
+//	  ...
+//	}
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	*err = fromPanic(r, 1)
+}
+
+func fromPanic(r any, skip int) error {
+	if r == nil {
+		return nil
+	}
+	je := &internal.Error{
+		Message: ErrPanic.Error(),
+		Code:    ErrPanic.(*internal.Error).Code,
+		Source:  getPanicSourceCode(skip + 1),
+		KV:      []models.KeyValue{{Key: "panic", Value: fmt.Sprint(r)}},
+	}
+	if err, ok := r.(error); ok {
+		je.Err = err
+	}
+	je.Binary, je.Trace = getPanicTrace(skip + 1)
+	return je
+}
@@ -0,0 +1,98 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/models"
+)
+
+func panicker(v any) {
+	panic(v)
+}
+
+func nilDereference() int {
+	var p *int
+	return *p
+}
+
+func recoverFrom(f func()) (err error) {
+	defer errors.Recover(&err)
+	f()
+	return nil
+}
+
+func TestFromPanic(t *testing.T) {
+	testCases := []struct {
+		name     string
+		do       func()
+		expMsg   string
+		expValue string
+		expIs    error
+	}{
+		{
+			name:     "string",
+			do:       func() { panicker("oh no") },
+			expMsg:   "panic",
+			expValue: "oh no",
+		},
+		{
+			name:     "error",
+			do:       func() { panicker(io.EOF) },
+			expMsg:   "panic: EOF",
+			expValue: "EOF",
+			expIs:    io.EOF,
+		},
+		{
+			name:     "runtime error",
+			do:       func() { nilDereference() },
+			expMsg:   "panic: runtime error: invalid memory address or nil pointer dereference",
+			expValue: "runtime error: invalid memory address or nil pointer dereference",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errors.SetTraceConfigTesting(t, errors.TestingConfig)
+			var err error
+			func() {
+				defer func() {
+					err = errors.FromPanic(recover())
+				}()
+				tc.do()
+			}()
+			require.Error(t, err)
+			assert.Equal(t, tc.expMsg, err.Error())
+			assert.True(t, errors.Is(err, errors.ErrPanic))
+			if tc.expIs != nil {
+				assert.True(t, errors.Is(err, tc.expIs))
+			}
+			assert.Equal(t, []models.KeyValue{{Key: "panic", Value: tc.expValue}}, err.(*internal.Error).KV)
+		})
+	}
+}
+
+func TestFromPanicNil(t *testing.T) {
+	assert.NoError(t, errors.FromPanic(nil))
+}
+
+func TestRecover(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	err := recoverFrom(func() { panicker("at the disco") })
+	require.Error(t, err)
+	je := err.(*internal.Error)
+	assert.Equal(t, "panic_test.go panicker", je.Source)
+	assert.NotEmpty(t, je.Binary)
+	assert.Equal(t, []string{
+		"panic_test.go panicker",
+		"panic_test.go recoverFrom",
+		"panic_test.go TestRecover",
+	}, je.GetStackTrace())
+
+	assert.NoError(t, recoverFrom(func() {}))
+}
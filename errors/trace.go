@@ -41,11 +41,10 @@ var TestingConfig = trace.StackConfig{
 	},
 }
 
-// getTrace will get the current binary and a stacktrace
+// getTrace will get the current process and capture a stacktrace
 // skip will omit a certain number of stack calls before getTrace
-func getTrace(skip int) (string, []string) {
-	// Skip GetStackTrace and getTrace
-	return trace.CurrentBinary(), trace.GetStackTrace(skip+1, traceConfig)
+func getTrace(skip int) (string, *trace.Trace) {
+	return trace.CurrentProcess(), trace.Capture(skip+1, traceConfig)
 }
 
 // getSourceCode will get the current
@@ -53,3 +52,14 @@ func getTrace(skip int) (string, []string) {
 func getSourceCode(skip int) string {
 	return trace.GetSourceCodeRef(skip+1, traceConfig)
 }
+
+// getPanicTrace will get the current process and a stacktrace from where
+// the current goroutine panicked
+func getPanicTrace(skip int) (string, *trace.Trace) {
+	return trace.CurrentProcess(), trace.CapturePanic(skip+1, traceConfig)
+}
+
+// getPanicSourceCode will get the source code reference of the panic
+func getPanicSourceCode(skip int) string {
+	return trace.GetPanicSourceCodeRef(skip+1, traceConfig)
+}
@@ -27,7 +27,7 @@ func TestSetTraceConfig(t *testing.T) {
 	}
 	SetTraceConfig(cfg)
 	_, st := getTrace(0)
-	assert.Equal(t, []string{"github.com/luno/jettison/errors:TestSetTraceConfig"}, st)
+	assert.Equal(t, []string{"github.com/luno/jettison/errors:TestSetTraceConfig"}, st.Lines())
 
 	assert.Panics(t, func() {
 		SetTraceConfig(trace.StackConfig{})
@@ -39,7 +39,7 @@ func TestSetTraceConfig(t *testing.T) {
 func TestStack(t *testing.T) {
 	SetTraceConfigTesting(t, TestingConfig)
 	err := stackCalls(5)
-	tr := []byte(strings.Join(err.StackTrace, "\n") + "\n")
+	tr := []byte(strings.Join(err.GetStackTrace(), "\n") + "\n")
 	goldie.New(t).Assert(t, t.Name(), tr)
 }
 
@@ -14,6 +14,7 @@ import (
 	"github.com/luno/jettison/internal"
 	"github.com/luno/jettison/j"
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
 // Error wraps an error and a status.
@@ -87,6 +88,9 @@ func toStatus(err error) *status.Status {
 		} else if errors.Is(err, context.DeadlineExceeded) {
 			c = codes.DeadlineExceeded
 		} else {
+			if errors.Is(err, errors.ErrPanic) {
+				c = codes.Internal
+			}
 			msg = err.Error()
 		}
 		s = status.New(c, msg)
@@ -130,6 +134,7 @@ func errorFromProto(we *jettisonpb.WrappedError) error {
 		Code:       we.Code,
 		Source:     we.Source,
 		StackTrace: we.StackTrace,
+		Frames:     framesFromProto(we.Frames),
 		KV:         kvFromProto(we.KeyValues),
 	}
 	if we.WrappedError != nil {
@@ -149,13 +154,14 @@ func errorToProto(err error) *jettisonpb.WrappedError {
 		we.Binary = removeNonUTF8(je.Binary)
 		we.Code = removeNonUTF8(je.Code)
 		we.Source = removeNonUTF8(je.Source)
-		if len(je.StackTrace) > 0 {
-			we.StackTrace = make([]string, len(je.StackTrace))
-			copy(we.StackTrace, je.StackTrace)
+		if st := je.GetStackTrace(); len(st) > 0 {
+			we.StackTrace = make([]string, len(st))
+			copy(we.StackTrace, st)
 			for i := range we.StackTrace {
 				we.StackTrace[i] = removeNonUTF8(we.StackTrace[i])
 			}
 		}
+		we.Frames = framesToProto(je.GetFrames())
 		we.KeyValues = kvToProto(je.KV)
 	}
 	switch unw := err.(type) {
@@ -200,6 +206,44 @@ func kvFromProto(kvs []*jettisonpb.KeyValue) []models.KeyValue {
 	return res
 }
 
+func framesToProto(frames []trace.Frame) []*jettisonpb.Frame {
+	if len(frames) == 0 {
+		return nil
+	}
+	res := make([]*jettisonpb.Frame, 0, len(frames))
+	for _, f := range frames {
+		res = append(res, &jettisonpb.Frame{
+			Function: removeNonUTF8(f.Function),
+			Package:  removeNonUTF8(f.Package),
+			File:     removeNonUTF8(f.File),
+			Line:     int64(f.Line),
+			Module:   removeNonUTF8(f.Module),
+			Version:  removeNonUTF8(f.Version),
+			Link:     removeNonUTF8(f.Link),
+		})
+	}
+	return res
+}
+
+func framesFromProto(frames []*jettisonpb.Frame) []trace.Frame {
+	if len(frames) == 0 {
+		return nil
+	}
+	res := make([]trace.Frame, 0, len(frames))
+	for _, f := range frames {
+		res = append(res, trace.Frame{
+			Function: f.Function,
+			Package:  f.Package,
+			File:     f.File,
+			Line:     int(f.Line),
+			Module:   f.Module,
+			Version:  f.Version,
+			Link:     f.Link,
+		})
+	}
+	return res
+}
+
 func removeNonUTF8(s string) string {
 	return strings.ToValidUTF8(s, "[snip]")
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/luno/jettison/j"
 	"github.com/luno/jettison/jtest"
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
 type source string
@@ -230,6 +231,37 @@ func TestToFromStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "frames",
+			err: &internal.Error{
+				Message:    "msg",
+				Binary:     "binary",
+				StackTrace: []string{"main.go:10 main"},
+				Frames: []trace.Frame{{
+					Function: "main",
+					Package:  "github.com/luno/example",
+					File:     "github.com/luno/example/main.go",
+					Line:     10,
+					Module:   "github.com/luno/example",
+					Version:  "v1.2.3",
+					Link:     "https://github.com/luno/example/blob/v1.2.3/main.go#L10",
+				}},
+			},
+			exp: &internal.Error{
+				Message:    "msg",
+				Binary:     "binary",
+				StackTrace: []string{"main.go:10 main"},
+				Frames: []trace.Frame{{
+					Function: "main",
+					Package:  "github.com/luno/example",
+					File:     "github.com/luno/example/main.go",
+					Line:     10,
+					Module:   "github.com/luno/example",
+					Version:  "v1.2.3",
+					Link:     "https://github.com/luno/example/blob/v1.2.3/main.go#L10",
+				}},
+			},
+		},
 		{
 			name: "non-utf8 in strings",
 			err: &internal.Error{
@@ -297,6 +329,27 @@ func TestToFromStatus(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "multi error fields",
+			err: func() error {
+				var m errors.Multi
+				m.AddIf(true, "name is required", errors.Field("name"), errors.WithoutStackTrace())
+				m.Add(io.EOF, errors.Field("body"), errors.WithoutStackTrace())
+				return m.ErrOrNil()
+			}(),
+			exp: errors.Join(
+				&internal.Error{
+					Message: "name is required",
+					Source:  "error_test.go TestToFromStatus.func2",
+					KV:      []models.KeyValue{{Key: "field", Value: "name"}},
+				},
+				&internal.Error{
+					Source: "error_test.go TestToFromStatus.func2",
+					KV:     []models.KeyValue{{Key: "field", Value: "body"}},
+					Err:    &internal.Error{Message: "EOF"},
+				},
+			),
+		},
 		{
 			name: "context deadline exceeded",
 			err:  context.DeadlineExceeded,
@@ -324,6 +377,24 @@ func TestToFromStatus(t *testing.T) {
 	}
 }
 
+func TestFramesToFromStatus(t *testing.T) {
+	cfg := errors.TestingConfig
+	cfg.IncludeFrames = true
+	errors.SetTraceConfigTesting(t, cfg)
+
+	err := errors.New("traced")
+	frames := err.(*internal.Error).GetFrames()
+	require.Len(t, frames, 1)
+	assert.Equal(t, "TestFramesToFromStatus", frames[0].Function)
+	assert.Equal(t, "github.com/luno/jettison/grpc", frames[0].Package)
+	assert.Equal(t, "github.com/luno/jettison/grpc/error_test.go", frames[0].File)
+	assert.NotZero(t, frames[0].Line)
+
+	je, ok := fromStatus(toStatus(err))
+	require.True(t, ok)
+	assert.Equal(t, frames, je.(*internal.Error).Frames)
+}
+
 func errorEqual(t *testing.T, exp, act error) {
 	expJe, ok := exp.(*internal.Error)
 	if !ok {
@@ -336,6 +407,7 @@ func errorEqual(t *testing.T, exp, act error) {
 	assert.Equal(t, expJe.StackTrace, je.StackTrace)
 	assert.Equal(t, expJe.Code, je.Code)
 	assert.Equal(t, expJe.Source, je.Source)
+	assert.Equal(t, expJe.Frames, je.Frames)
 	assert.Equal(t, expJe.KV, je.KV)
 	errorEqual(t, expJe.Err, je.Err)
 }
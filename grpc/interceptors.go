@@ -60,6 +60,37 @@ func StreamServerInterceptor(
 	return outgoingError(err)
 }
 
+// UnaryServerRecoveryInterceptor recovers from panics in the handler,
+// returning them as jettison errors with an Internal status code.
+func UnaryServerRecoveryInterceptor(ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (_ any, err error) {
+	defer func() {
+		if pErr := errors.FromPanic(recover()); pErr != nil {
+			err = outgoingError(pErr)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// StreamServerRecoveryInterceptor recovers from panics in the handler,
+// returning them as jettison errors with an Internal status code.
+func StreamServerRecoveryInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if pErr := errors.FromPanic(recover()); pErr != nil {
+			err = outgoingError(pErr)
+		}
+	}()
+	return handler(srv, ss)
+}
+
 // incomingError converts all non-nil errors into jettison errors.
 // a new stack trace is added representing the stack in this new binary.
 func incomingError(err error) error {
@@ -74,6 +105,10 @@ func outgoingError(err error) error {
 	if err == nil {
 		return nil
 	}
+	// Avoid adding the details again if we've already converted this error
+	if gErr, ok := err.(Error); ok {
+		return gErr
+	}
 	return Wrap(err)
 }
 
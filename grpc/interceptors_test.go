@@ -4,9 +4,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/luno/jettison/errors"
 	"github.com/luno/jettison/jtest"
 )
 
@@ -36,3 +40,118 @@ func TestErrIntercept(t *testing.T) {
 		})
 	}
 }
+
+func TestRecoveryIntercept(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler grpc.UnaryHandler
+		expCode codes.Code
+		expErr  error
+	}{
+		{
+			name: "no panic",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, nil
+			},
+		},
+		{
+			name: "error is passed through",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, context.Canceled
+			},
+			expCode: codes.Canceled,
+			expErr:  context.Canceled,
+		},
+		{
+			name: "panic gets internal",
+			handler: func(ctx context.Context, req any) (any, error) {
+				panic("oh no")
+			},
+			expCode: codes.Internal,
+			expErr:  errors.ErrPanic,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := UnaryServerRecoveryInterceptor(context.Background(), nil, nil,
+				func(ctx context.Context, req any) (any, error) {
+					return UnaryServerInterceptor(ctx, req, nil, tc.handler)
+				},
+			)
+			if tc.expErr == nil {
+				jtest.RequireNil(t, err)
+				return
+			}
+			s, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tc.expCode, s.Code())
+			assert.Len(t, s.Details(), 1)
+
+			jtest.Require(t, tc.expErr, incomingError(err))
+		})
+	}
+}
+
+// testServerStream is a grpc.ServerStream which only has a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *testServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestStreamRecoveryIntercept(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler grpc.StreamHandler
+		expCode codes.Code
+		expErr  error
+	}{
+		{
+			name: "no panic",
+			handler: func(srv any, ss grpc.ServerStream) error {
+				return nil
+			},
+		},
+		{
+			name: "error is passed through",
+			handler: func(srv any, ss grpc.ServerStream) error {
+				return context.Canceled
+			},
+			expCode: codes.Canceled,
+			expErr:  context.Canceled,
+		},
+		{
+			name: "panic gets internal",
+			handler: func(srv any, ss grpc.ServerStream) error {
+				panic("oh no")
+			},
+			expCode: codes.Internal,
+			expErr:  errors.ErrPanic,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ss := &testServerStream{ctx: context.Background()}
+			err := StreamServerRecoveryInterceptor(nil, ss, nil,
+				func(srv any, ss grpc.ServerStream) error {
+					return StreamServerInterceptor(srv, ss, nil, tc.handler)
+				},
+			)
+			if tc.expErr == nil {
+				jtest.RequireNil(t, err)
+				return
+			}
+			s, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tc.expCode, s.Code())
+			assert.Len(t, s.Details(), 1)
+
+			jtest.Require(t, tc.expErr, incomingError(err))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 // This file is used to compile the jettisonpb package's proto files.
 // Usage: go generate <path to this directory>
+//
+// The generated code is pinned to protoc v4.22.2 and protoc-gen-go v1.32.0,
+// install those versions before regenerating to avoid changing unrelated code.
 
-//go:generate protoc --go_out=plugins=grpc:. ./jettison.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative ./jettison.proto
 
 package jettisonpb
@@ -0,0 +1,13 @@
+package internal
+
+import "strings"
+
+// CutLast slices s around the last instance of sep, returning the text before and after sep.
+// If sep doesn't appear in s, CutLast returns s, "", false.
+func CutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
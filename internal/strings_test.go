@@ -0,0 +1,33 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luno/jettison/internal"
+)
+
+func TestCutLast(t *testing.T) {
+	testCases := []struct {
+		name      string
+		s         string
+		expBefore string
+		expAfter  string
+		expFound  bool
+	}{
+		{name: "empty", s: "", expBefore: ""},
+		{name: "not found", s: "file.go", expBefore: "file.go"},
+		{name: "one", s: "file.go:12", expBefore: "file.go", expAfter: "12", expFound: true},
+		{name: "last", s: "c:/file.go:12", expBefore: "c:/file.go", expAfter: "12", expFound: true},
+		{name: "at end", s: "file.go:", expBefore: "file.go", expFound: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before, after, found := internal.CutLast(tc.s, ":")
+			assert.Equal(t, tc.expBefore, before)
+			assert.Equal(t, tc.expAfter, after)
+			assert.Equal(t, tc.expFound, found)
+		})
+	}
+}
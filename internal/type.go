@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
 type Error struct {
@@ -16,9 +18,30 @@ type Error struct {
 
 	Binary     string
 	StackTrace []string
-	Code       string
-	Source     string
-	KV         []models.KeyValue
+	// Trace is a stack trace which is formatted on demand,
+	// it takes precedence over StackTrace when set
+	Trace *trace.Trace
+	// Frames are the structured calls of StackTrace
+	Frames []trace.Frame
+	Code   string
+	Source string
+	KV     []models.KeyValue
+}
+
+// GetStackTrace returns the formatted stack trace of this error
+func (je *Error) GetStackTrace() []string {
+	if je.Trace != nil {
+		return je.Trace.Lines()
+	}
+	return je.StackTrace
+}
+
+// GetFrames returns the structured calls of the stack trace of this error
+func (je *Error) GetFrames() []trace.Frame {
+	if je.Trace != nil {
+		return je.Trace.Frames()
+	}
+	return je.Frames
 }
 
 // Format satisfies the fmt.Formatter interface providing customizable formatting:
@@ -124,3 +147,15 @@ var (
 	_ fmt.Formatter   = (*Error)(nil)
 	_ xerrors.Printer = (*printer)(nil)
 )
+
+// MessageOf returns the message of err without the messages of the errors it wraps
+func MessageOf(err error) string {
+	if je, ok := err.(*Error); ok {
+		return je.Message
+	}
+	msg := err.Error()
+	if inner := errors.Unwrap(err); inner != nil {
+		msg = strings.TrimSuffix(strings.TrimSuffix(msg, inner.Error()), ": ")
+	}
+	return msg
+}
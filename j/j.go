@@ -5,7 +5,8 @@ package j
 import (
 	"fmt"
 	"reflect"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/luno/jettison/errors"
@@ -49,18 +50,21 @@ func KS(key string, value string) MKV {
 type MKV map[string]any
 
 func (m MKV) ContextKeys() []models.KeyValue {
-	res := make([]models.KeyValue, 0, len(m))
+	return m.appendKeys(make([]models.KeyValue, 0, len(m)))
+}
+
+// appendKeys appends the sorted key values to kvs
+func (m MKV) appendKeys(kvs []models.KeyValue) []models.KeyValue {
+	n := len(kvs)
 	for k, v := range m {
-		res = append(res, models.KeyValue{Key: normalise(k), Value: sprint(v)})
+		kvs = append(kvs, models.KeyValue{Key: normalise(k), Value: sprint(v)})
 	}
-	sort.Slice(res, func(i, j int) bool {
-		return res[i].Key < res[j].Key
-	})
-	return res
+	sortKeys(kvs[n:])
+	return kvs
 }
 
 func (m MKV) ApplyToLog(l *log.Entry) {
-	l.Parameters = append(l.Parameters, m.ContextKeys()...)
+	l.Parameters = m.appendKeys(l.Parameters)
 }
 
 func (m MKV) ApplyToError(je *internal.Error) {
@@ -74,18 +78,27 @@ func (m MKV) ApplyToError(je *internal.Error) {
 type MKS map[string]string
 
 func (m MKS) ContextKeys() []models.KeyValue {
-	res := make([]models.KeyValue, 0, len(m))
+	return m.appendKeys(make([]models.KeyValue, 0, len(m)))
+}
+
+// appendKeys appends the sorted key values to kvs
+func (m MKS) appendKeys(kvs []models.KeyValue) []models.KeyValue {
+	n := len(kvs)
 	for k, v := range m {
-		res = append(res, models.KeyValue{Key: normalise(k), Value: v})
+		kvs = append(kvs, models.KeyValue{Key: normalise(k), Value: v})
 	}
-	sort.Slice(res, func(i, j int) bool {
-		return res[i].Key < res[j].Key
-	})
-	return res
+	sortKeys(kvs[n:])
+	return kvs
 }
 
 func (m MKS) ApplyToLog(l *log.Entry) {
-	l.Parameters = append(l.Parameters, m.ContextKeys()...)
+	l.Parameters = m.appendKeys(l.Parameters)
+}
+
+func sortKeys(kvs []models.KeyValue) {
+	slices.SortFunc(kvs, func(a, b models.KeyValue) int {
+		return strings.Compare(a.Key, b.Key)
+	})
 }
 
 func (m MKS) ApplyToError(je *internal.Error) {
@@ -118,15 +131,15 @@ func sprint(i interface{}) string {
 	}
 
 	// Shortcut some simple types
-	switch i.(type) {
+	switch v := i.(type) {
 	case bool:
-		return fmt.Sprint(i)
+		return strconv.FormatBool(v)
 	case int:
-		return fmt.Sprint(i)
+		return strconv.Itoa(v)
 	case int64:
-		return fmt.Sprint(i)
+		return strconv.FormatInt(v, 10)
 	case string:
-		return fmt.Sprint(i)
+		return v
 	case fmt.Stringer:
 		return fmt.Sprint(i)
 	case fmt.Formatter:
@@ -144,6 +157,10 @@ func sprint(i interface{}) string {
 // instance).
 // See https://godoc.org/google.golang.org/grpc/metadata#New.
 func normalise(key string) string {
+	if isNormalised(key) {
+		return key
+	}
+
 	// Uppercase characters are normalised to lower case.
 	key = strings.ToLower(key)
 
@@ -162,3 +179,21 @@ func normalise(key string) string {
 
 	return res.String()
 }
+
+// NormaliseKey returns the key which is used when key is given to KV, KS, MKV or MKS
+func NormaliseKey(key string) string {
+	return normalise(key)
+}
+
+// isNormalised returns true if normalise wouldn't change key
+func isNormalised(key string) bool {
+	if strings.HasPrefix(key, "grpc-") {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(allowedChars, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -167,12 +167,12 @@ func TestC(t *testing.T) {
 	errFoo := errors.New("foo", C("123"))
 
 	je := errFoo.(*internal.Error)
-	require.Empty(t, je.StackTrace)
+	require.Empty(t, je.GetStackTrace())
 	require.Equal(t, "123", je.Code)
 
 	err := errors.Wrap(errFoo, "wrap adds stacktrace")
 	je = err.(*internal.Error)
-	require.NotEmpty(t, je.StackTrace)
+	require.NotEmpty(t, je.GetStackTrace())
 	require.True(t, errors.Is(err, errFoo))
 }
 
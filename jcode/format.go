@@ -0,0 +1,112 @@
+package jcode
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Format is a format of jettison error codes
+type Format struct {
+	Name string
+	// Gen returns a new code for the variable in the package
+	Gen func(pkg, variable string) string
+	// Valid returns true if code is a valid code for the variable in the package
+	Valid func(pkg, variable, code string) bool
+}
+
+var (
+	// FormatBase64 codes are random base64 strings of length 8
+	FormatBase64 = Format{Name: "base64_8", Gen: fmtBase64, Valid: validBase64}
+	// FormatErrHex codes are "ERR_" followed by a random 16 character hex string
+	FormatErrHex = Format{Name: "err_hex_16", Gen: fmtErrHex, Valid: validErrHex}
+	// FormatHash codes are "ERR_" followed by 16 hex characters of the hash of
+	// the package path and variable name, so the same sentinel always gets the same code
+	FormatHash = Format{Name: "hash_16", Gen: fmtHash, Valid: validGen(fmtHash)}
+	// FormatPkgVar codes are the package path and variable name, e.g. "github.com/luno/jettison/errors.ErrPanic"
+	FormatPkgVar = Format{Name: "pkg_var", Gen: fmtPkgVar, Valid: validGen(fmtPkgVar)}
+)
+
+// Formats are the formats which can be selected by name
+var Formats = []Format{FormatBase64, FormatErrHex, FormatHash, FormatPkgVar}
+
+// FormatNames returns the names of the formats in Formats
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// FormatByName returns the format in Formats with the name
+func FormatByName(name string) (Format, bool) {
+	for _, f := range Formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// fmtBase64 returns a base64 string of length 8; which is equivalent to a random uint48.
+func fmtBase64(_, _ string) string {
+	b := make([]byte, 6)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "sorry error"
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func validBase64(_, _, code string) bool {
+	if len(code) != 8 {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(code)
+	return err == nil
+}
+
+// fmtErrHex returns string that matches the following regex "ERR_[0-9a-f]{16}",
+// ie. "ERR_" followed by random 16 character hex string.
+func fmtErrHex(_, _ string) string {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "sorry error"
+	}
+	return fmt.Sprintf("ERR_%x", b)
+}
+
+func validErrHex(_, _, code string) bool {
+	s := strings.Split(code, "_")
+	if len(s) != 2 {
+		return false
+	}
+	if s[0] != "ERR" {
+		return false
+	}
+	_, err := hex.DecodeString(s[1])
+	return err == nil
+}
+
+// fmtHash returns "ERR_" followed by the first 16 hex characters of the
+// SHA-256 hash of the package path and variable name.
+func fmtHash(pkg, variable string) string {
+	h := sha256.Sum256([]byte(pkg + "." + variable))
+	return fmt.Sprintf("ERR_%x", h[:8])
+}
+
+func fmtPkgVar(pkg, variable string) string {
+	return pkg + "." + variable
+}
+
+// validGen returns a Valid func for formats which always generate the same code
+func validGen(gen func(pkg, variable string) string) func(pkg, variable, code string) bool {
+	return func(pkg, variable, code string) bool {
+		return code == gen(pkg, variable)
+	}
+}
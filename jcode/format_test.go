@@ -0,0 +1,59 @@
+package jcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase64(t *testing.T) {
+	uuid := fmtBase64("", "")
+	assert.Len(t, uuid, 8)
+	assert.True(t, validBase64("", "", uuid))
+}
+
+func TestErrHex4(t *testing.T) {
+	code := fmtErrHex("", "")
+	assert.Len(t, code, 4+16)
+	assert.True(t, validErrHex("", "", code))
+}
+
+func TestDeterministicFormats(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format Format
+		exp    string
+	}{
+		{name: "hash", format: FormatHash, exp: "ERR_2ccf82c3d18289e7"},
+		{name: "pkg_var", format: FormatPkgVar, exp: "github.com/luno/jettison/errors.ErrPanic"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code := tc.format.Gen("github.com/luno/jettison/errors", "ErrPanic")
+			assert.Equal(t, tc.exp, code)
+			assert.Equal(t, code, tc.format.Gen("github.com/luno/jettison/errors", "ErrPanic"))
+			assert.True(t, tc.format.Valid("github.com/luno/jettison/errors", "ErrPanic", code))
+
+			assert.NotEqual(t, code, tc.format.Gen("github.com/luno/jettison/log", "ErrPanic"))
+			assert.False(t, tc.format.Valid("github.com/luno/jettison/log", "ErrPanic", code))
+		})
+	}
+}
+
+func TestFormatHash(t *testing.T) {
+	code := fmtHash("github.com/luno/jettison/errors", "ErrPanic")
+	assert.Len(t, code, 4+16)
+	// Hash codes are also valid hex codes
+	assert.True(t, validErrHex("", "", code))
+}
+
+func TestFormatByName(t *testing.T) {
+	for _, name := range FormatNames() {
+		f, ok := FormatByName(name)
+		require.True(t, ok)
+		assert.Equal(t, name, f.Name)
+	}
+	_, ok := FormatByName("unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,287 @@
+// Package jcode provides analyzers which check the use of jettison.
+//
+// Analyzer checks that sentinel errors, package level variables created with
+// errors.New, have jettison error codes in the expected format. Without a code,
+// errors.Is compares jettison errors by their messages. WrapAnalyzer and
+// KVAnalyzer check the messages and key/values given to errors and logs.
+//
+// They can be used with go vet by building cmd/jcodevet:
+//
+//	go vet -vettool=$(which jcodevet) ./...
+package jcode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+const (
+	errorsPath = "github.com/luno/jettison/errors"
+	jPath      = "github.com/luno/jettison/j"
+)
+
+// Analyzers are all the analyzers in the package
+var Analyzers = []*analysis.Analyzer{Analyzer, WrapAnalyzer, KVAnalyzer}
+
+var Analyzer = &analysis.Analyzer{
+	Name: "jcode",
+	Doc:  "check that sentinel errors have jettison error codes and no stack traces",
+	Run:  run,
+}
+
+var formatName = FormatErrHex.Name
+
+func init() {
+	Analyzer.Flags.StringVar(&formatName, "format", formatName, "error code format, one of "+strings.Join(FormatNames(), ", "))
+}
+
+// NewAnalyzer returns an analyzer like Analyzer which checks for the format,
+// rather than the one given by the -format flag
+func NewAnalyzer(format Format) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: Analyzer.Name,
+		Doc:  Analyzer.Doc,
+		Run: func(pass *analysis.Pass) (any, error) {
+			checkSentinels(pass, format)
+			return nil, nil
+		},
+	}
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	format, ok := FormatByName(formatName)
+	if !ok {
+		return nil, errors.New("unknown format", j.KV("format", formatName))
+	}
+	checkSentinels(pass, format)
+	return nil, nil
+}
+
+// Sentinel is a package level variable created with errors.New
+type Sentinel struct {
+	// Decl is the declaration of the variable, which may declare others
+	Decl *ast.GenDecl
+	Spec *ast.ValueSpec
+	Name *ast.Ident
+	// Call is the call of errors.New
+	Call *ast.CallExpr
+	// CodeCall is the option which sets the code, j.C, errors.C or errors.WithCode,
+	// it's nil when there's no code
+	CodeCall *ast.CallExpr
+	// ClearsTrace is true when an option removes the stack trace
+	ClearsTrace bool
+}
+
+// FindSentinels returns the sentinels declared in the file, in order
+func FindSentinels(info *types.Info, f *ast.File) []Sentinel {
+	var sentinels []Sentinel
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != len(vs.Values) {
+				continue
+			}
+			for i, name := range vs.Names {
+				call, ok := vs.Values[i].(*ast.CallExpr)
+				if !ok || !isFunc(info, call, errorsPath, "New") {
+					continue
+				}
+				sentinels = append(sentinels, newSentinel(info, gd, vs, name, call))
+			}
+		}
+	}
+	return sentinels
+}
+
+func newSentinel(info *types.Info, gd *ast.GenDecl, vs *ast.ValueSpec, name *ast.Ident, call *ast.CallExpr) Sentinel {
+	s := Sentinel{Decl: gd, Spec: vs, Name: name, Call: call}
+	for _, arg := range call.Args[1:] {
+		c, ok := arg.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		switch {
+		case isFunc(info, c, jPath, "C"), isFunc(info, c, errorsPath, "C"):
+			s.CodeCall = c
+			s.ClearsTrace = true
+		case isFunc(info, c, errorsPath, "WithCode"):
+			s.CodeCall = c
+		case isFunc(info, c, errorsPath, "WithoutStackTrace"):
+			s.ClearsTrace = true
+		}
+	}
+	return s
+}
+
+// Code returns the code given to CodeCall, and false if there's
+// no code or it isn't known until runtime
+func (s Sentinel) Code(info *types.Info) (string, bool) {
+	if s.CodeCall == nil || len(s.CodeCall.Args) != 1 {
+		return "", false
+	}
+	tv := info.Types[s.CodeCall.Args[0]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func checkSentinels(pass *analysis.Pass, format Format) {
+	for _, f := range pass.Files {
+		imp := newJImport(f)
+		for _, s := range FindSentinels(pass.TypesInfo, f) {
+			checkSentinel(pass, imp, format, s)
+		}
+	}
+}
+
+func checkSentinel(pass *analysis.Pass, imp *jImport, format Format, s Sentinel) {
+	name, call := s.Name, s.Call
+	pkg := pass.Pkg.Path()
+	if s.CodeCall == nil {
+		jName, edits := imp.use()
+		last := call.Args[len(call.Args)-1]
+		edits = append(edits, analysis.TextEdit{
+			Pos:     last.End(),
+			End:     last.End(),
+			NewText: fmt.Appendf(nil, ", %s.C(%q)", jName, format.Gen(pkg, name.Name)),
+		})
+		pass.Report(analysis.Diagnostic{
+			Pos:     name.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("%s: missing jettison code", name.Name),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Add a new code",
+				TextEdits: edits,
+			}},
+		})
+		return
+	}
+
+	checkCode(pass, format, s)
+
+	// The stack trace of a sentinel is captured during init, which isn't useful
+	if !s.ClearsTrace {
+		jName, edits := imp.use()
+		edits = append(edits, analysis.TextEdit{
+			Pos:     s.CodeCall.Fun.Pos(),
+			End:     s.CodeCall.Fun.End(),
+			NewText: []byte(jName + ".C"),
+		})
+		pass.Report(analysis.Diagnostic{
+			Pos:     s.CodeCall.Pos(),
+			End:     s.CodeCall.End(),
+			Message: fmt.Sprintf("%s: sentinel error has a stack trace, use j.C or errors.WithoutStackTrace", name.Name),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Use j.C",
+				TextEdits: edits,
+			}},
+		})
+	}
+}
+
+// checkCode checks the format of the code given to j.C or errors.WithCode
+func checkCode(pass *analysis.Pass, format Format, s Sentinel) {
+	code, ok := s.Code(pass.TypesInfo)
+	if !ok {
+		return
+	}
+	pkg := pass.Pkg.Path()
+	if format.Valid(pkg, s.Name.Name, code) {
+		return
+	}
+	arg := s.CodeCall.Args[0]
+	d := analysis.Diagnostic{
+		Pos:     arg.Pos(),
+		End:     arg.End(),
+		Message: fmt.Sprintf("%s: incorrect jettison code %q", s.Name.Name, code),
+	}
+	if lit, ok := arg.(*ast.BasicLit); ok {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Generate a new code",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     lit.Pos(),
+				End:     lit.End(),
+				NewText: []byte(strconv.Quote(format.Gen(pkg, s.Name.Name))),
+			}},
+		}}
+	}
+	pass.Report(d)
+}
+
+// isFunc returns true if call is a call of the function in the package
+func isFunc(info *types.Info, call *ast.CallExpr, pkgPath, name string) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkgPath && fn.Name() == name
+}
+
+// jImport adds the import of jettison/j to a file for suggested fixes which use it
+type jImport struct {
+	name  string
+	edits []analysis.TextEdit
+}
+
+func newJImport(f *ast.File) *jImport {
+	name, edits := importJ(f)
+	return &jImport{name: name, edits: edits}
+}
+
+// use returns the name of jettison/j in the file, and the edits to import it
+// for the first fix which uses it, so that applying every fix only imports it once
+func (i *jImport) use() (string, []analysis.TextEdit) {
+	edits := i.edits
+	i.edits = nil
+	return i.name, edits
+}
+
+// importJ returns the name the file uses for jettison/j, and the edits to import it if it isn't already
+func importJ(f *ast.File) (string, []analysis.TextEdit) {
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != jPath {
+			continue
+		}
+		if spec.Name == nil {
+			return "j", nil
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name, nil
+		}
+	}
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		if gd.Lparen.IsValid() {
+			return "j", []analysis.TextEdit{{
+				Pos:     gd.Rparen,
+				End:     gd.Rparen,
+				NewText: []byte("\t" + strconv.Quote(jPath) + "\n"),
+			}}
+		}
+		return "j", []analysis.TextEdit{{
+			Pos:     gd.End(),
+			End:     gd.End(),
+			NewText: []byte("\nimport " + strconv.Quote(jPath)),
+		}}
+	}
+	// errors must have been imported to call errors.New
+	return "j", nil
+}
@@ -0,0 +1,52 @@
+package jcode
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	// Generate codes which are the same each time
+	oldFormats, oldName := Formats, formatName
+	t.Cleanup(func() {
+		Formats, formatName = oldFormats, oldName
+	})
+	Formats = append(slices.Clip(Formats), Format{
+		Name:  "test",
+		Gen:   func(pkg, variable string) string { return "ERR_" + pkg + "_" + variable },
+		Valid: FormatErrHex.Valid,
+	})
+	formatName = "test"
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a", "b", "c")
+}
+
+func TestWrapAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), WrapAnalyzer, "wrap")
+}
+
+func TestKVAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), KVAnalyzer, "kv")
+}
+
+func TestImportOnce(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer, "c")
+	require.Len(t, results, 1)
+
+	var imports int
+	for _, d := range results[0].Diagnostics {
+		for _, fix := range d.SuggestedFixes {
+			for _, e := range fix.TextEdits {
+				if strings.Contains(string(e.NewText), jPath) {
+					imports++
+				}
+			}
+		}
+	}
+	assert.Equal(t, 1, imports)
+}
@@ -0,0 +1,140 @@
+package jcode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/luno/jettison/j"
+)
+
+var KVAnalyzer = &analysis.Analyzer{
+	Name: "jkv",
+	Doc:  "check that j.KV keys aren't changed by normalisation and that the values can be printed",
+	Run:  runKV,
+}
+
+func runKV(pass *analysis.Pass) (any, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				if len(n.Args) != 2 {
+					return true
+				}
+				if isFunc(pass.TypesInfo, n, jPath, "KV") {
+					checkKey(pass, n.Args[0])
+					checkValue(pass, n.Args[1])
+				} else if isFunc(pass.TypesInfo, n, jPath, "KS") {
+					checkKey(pass, n.Args[0])
+				}
+			case *ast.CompositeLit:
+				typ := pass.TypesInfo.TypeOf(n)
+				isMKV, isMKS := isJType(typ, "MKV"), isJType(typ, "MKS")
+				if !isMKV && !isMKS {
+					return true
+				}
+				for _, elt := range n.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					checkKey(pass, kv.Key)
+					if isMKV {
+						checkValue(pass, kv.Value)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// isJType returns true if typ is the named type in jettison/j
+func isJType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == jPath && obj.Name() == name
+}
+
+// checkKey reports constant keys which would be changed by j.NormaliseKey
+func checkKey(pass *analysis.Pass, key ast.Expr) {
+	tv := pass.TypesInfo.Types[key]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	k := constant.StringVal(tv.Value)
+	norm := j.NormaliseKey(k)
+	if norm == k {
+		return
+	}
+	d := analysis.Diagnostic{
+		Pos:     key.Pos(),
+		End:     key.End(),
+		Message: fmt.Sprintf("key %q is normalised to %q", k, norm),
+	}
+	if lit, ok := key.(*ast.BasicLit); ok && norm != "" {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Use the normalised key",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     lit.Pos(),
+				End:     lit.End(),
+				NewText: []byte(strconv.Quote(norm)),
+			}},
+		}}
+	}
+	pass.Report(d)
+}
+
+// checkValue reports values which j.KV doesn't print, e.g. structs and slices
+// are written as <struct> and <slice> unless they implement fmt.Stringer
+func checkValue(pass *analysis.Pass, value ast.Expr) {
+	typ := pass.TypesInfo.TypeOf(value)
+	if typ == nil || types.IsInterface(typ) || hasMethod(typ, "String") || hasMethod(typ, "Format") {
+		return
+	}
+	var kind string
+	switch u := typ.Underlying().(type) {
+	case *types.Struct:
+		kind = "struct"
+	case *types.Map:
+		kind = "map"
+	case *types.Slice:
+		kind = "slice"
+	case *types.Array:
+		kind = "array"
+	case *types.Pointer:
+		kind = "ptr"
+	case *types.Signature:
+		kind = "func"
+	case *types.Chan:
+		kind = "chan"
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Uintptr:
+			kind = "uintptr"
+		case types.UnsafePointer:
+			kind = "unsafe.Pointer"
+		}
+	}
+	if kind == "" {
+		return
+	}
+	pass.Reportf(value.Pos(), "value of type %s is written as <%s>, use a simple value or implement fmt.Stringer",
+		types.TypeString(typ, types.RelativeTo(pass.Pkg)), kind)
+}
+
+// hasMethod returns true if the method set of typ has the method
+func hasMethod(typ types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, false, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
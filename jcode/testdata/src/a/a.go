@@ -0,0 +1,40 @@
+package a
+
+import (
+	stderrors "errors"
+
+	jerrors "github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+const code = "ERR_0123456789abcdef"
+
+var ErrMissing = jerrors.New("missing") // want `ErrMissing: missing jettison code`
+
+var (
+	// ErrWithC has a valid code
+	ErrWithC = jerrors.New("with c", j.C("ERR_0123456789abcdef"))
+	// ErrWithoutTrace has a valid code and no stack trace
+	ErrWithoutTrace = jerrors.New("without trace", jerrors.WithCode("ERR_0123456789abcdef"), jerrors.WithoutStackTrace())
+	// ErrErrorsC has a valid code
+	ErrErrorsC = jerrors.New("errors c", jerrors.C("ERR_0123456789abcdef"))
+
+	ErrWithCode = jerrors.New("with code", jerrors.WithCode("ERR_0123456789abcdef")) // want `ErrWithCode: sentinel error has a stack trace, use j.C or errors.WithoutStackTrace`
+	// ErrConst has a valid code from a constant
+	ErrConst = jerrors.New("const", j.C(code))
+
+	ErrInvalidC    = jerrors.New("invalid c", j.C("not a code"))              // want `ErrInvalidC: incorrect jettison code "not a code"`
+	ErrInvalidCode = jerrors.New("invalid code", jerrors.WithCode("ERR_xyz")) // want `ErrInvalidCode: incorrect jettison code "ERR_xyz"` `ErrInvalidCode: sentinel error has a stack trace`
+	ErrGrouped     = jerrors.New("grouped",                                   // want `ErrGrouped: missing jettison code`
+		jerrors.WithoutStackTrace(),
+	)
+
+	ErrOne, ErrTwo = jerrors.New("one"), jerrors.New("two", j.C("ERR_0123456789abcdef")) // want `ErrOne: missing jettison code`
+
+	ErrStd = stderrors.New("std")
+)
+
+func f() error {
+	errLocal := jerrors.New("local")
+	return errLocal
+}
@@ -0,0 +1,5 @@
+package b
+
+import "github.com/luno/jettison/errors"
+
+var ErrMissing = errors.New("missing") // want `ErrMissing: missing jettison code`
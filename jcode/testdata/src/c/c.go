@@ -0,0 +1,16 @@
+package c
+
+import (
+	"fmt"
+
+	"github.com/luno/jettison/errors"
+)
+
+var (
+	ErrOne = errors.New("one") // want `ErrOne: missing jettison code`
+	ErrTwo = errors.New("two") // want `ErrTwo: missing jettison code`
+)
+
+func f() {
+	fmt.Println(ErrOne, ErrTwo)
+}
@@ -0,0 +1,13 @@
+package errors
+
+type Option interface{}
+
+func New(msg string, ol ...Option) error { return nil }
+
+func WithCode(code string) Option { return nil }
+
+func WithoutStackTrace() Option { return nil }
+
+func Wrap(err error, msg string, ol ...Option) error { return nil }
+
+func C(code string) Option { return nil }
@@ -0,0 +1,13 @@
+package j
+
+import "github.com/luno/jettison/errors"
+
+func C(code string) errors.Option { return nil }
+
+type MKV map[string]any
+
+type MKS map[string]string
+
+func KV(key string, value any) MKV { return MKV{key: value} }
+
+func KS(key string, value string) MKV { return MKV{key: value} }
@@ -0,0 +1,45 @@
+package kv
+
+import (
+	"time"
+
+	"github.com/luno/jettison/j"
+)
+
+type account struct{ id int }
+
+type named struct{}
+
+func (named) String() string { return "named" }
+
+type pointerNamed struct{}
+
+func (*pointerNamed) String() string { return "pointer named" }
+
+const key = "Const_Key"
+
+func f(acc account, ids []int, err error) []j.MKV {
+	return []j.MKV{
+		j.KV("account_id", acc.id),
+		j.KV("AccountID", acc.id),  // want `key "AccountID" is normalised to "accountid"`
+		j.KS("grpc-status", "ok"),  // want `key "grpc-status" is normalised to "status"`
+		j.KS("user name", "alice"), // want `key "user name" is normalised to "username"`
+		j.KV("!", 1),               // want `key "!" is normalised to ""`
+		j.KV(key, 1),               // want `key "Const_Key" is normalised to "const_key"`
+		j.KV("account", acc),       // want `value of type account is written as <struct>`
+		j.KV("ids", ids),           // want `value of type \[\]int is written as <slice>`
+		j.KV("account_ptr", &acc),  // want `value of type \*account is written as <ptr>`
+		j.KV("err", err),
+		j.KV("named", named{}),
+		j.KV("pointer_named", &pointerNamed{}),
+		j.KV("duration", time.Second),
+		{
+			"Map_Key": 1,   // want `key "Map_Key" is normalised to "map_key"`
+			"ids":     ids, // want `value of type \[\]int is written as <slice>`
+		},
+	}
+}
+
+func g() j.MKS {
+	return j.MKS{"Key": "value"} // want `key "Key" is normalised to "key"`
+}
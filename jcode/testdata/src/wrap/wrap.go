@@ -0,0 +1,18 @@
+package wrap
+
+import (
+	"fmt"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+func f(err error, id int) error {
+	if id == 0 {
+		return errors.Wrap(err, "constant message", j.KV("id", id))
+	}
+	if id == 1 {
+		return errors.Wrap(err, fmt.Sprint("not formatted"))
+	}
+	return errors.Wrap(err, fmt.Sprintf("lookup %d", id)) // want `errors.Wrap message is formatted, add values with j.KV so the message can be matched`
+}
@@ -0,0 +1,30 @@
+package jcode
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var WrapAnalyzer = &analysis.Analyzer{
+	Name: "jwrap",
+	Doc:  "check that errors.Wrap messages are constant, values should be added with j.KV",
+	Run:  runWrap,
+}
+
+func runWrap(pass *analysis.Pass) (any, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) < 2 || !isFunc(pass.TypesInfo, call, errorsPath, "Wrap") {
+				return true
+			}
+			msg, ok := call.Args[1].(*ast.CallExpr)
+			if ok && isFunc(pass.TypesInfo, msg, "fmt", "Sprintf") {
+				pass.Reportf(msg.Pos(), "errors.Wrap message is formatted, add values with j.KV so the message can be matched")
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
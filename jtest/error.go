@@ -0,0 +1,177 @@
+package jtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+)
+
+// AssertCode asserts that an error in err has the code. Only codes set on
+// jettison errors are compared, not the messages used by errors.GetCodes
+// for errors without a code.
+//
+//	jtest.AssertCode(t, "ERR_48026e342952be11", err)
+func AssertCode(t testing.TB, code string, err error, msgs ...any) bool {
+	t.Helper()
+
+	if findError(err, func(err error) bool {
+		je, ok := err.(*internal.Error)
+		return ok && je.Code != "" && je.Code == code
+	}) {
+		return true
+	}
+	t.Error(failLogf(err, msgs, "Expected error code '%v' was not present in actual error", code))
+	return false
+}
+
+// RequireCode asserts that an error in err has the code,
+// the test will terminate immediately if it doesn't.
+// See AssertCode for more details
+func RequireCode(t testing.TB, code string, err error, msgs ...any) {
+	t.Helper()
+
+	if !AssertCode(t, code, err, msgs...) {
+		t.FailNow()
+	}
+}
+
+// AssertWrappedMessage asserts that err was created or wrapped with the message.
+// Messages are compared without the messages of the errors they wrap.
+//
+//	jtest.AssertWrappedMessage(t, "lookup account", err)
+func AssertWrappedMessage(t testing.TB, msg string, err error, msgs ...any) bool {
+	t.Helper()
+
+	if findError(err, func(err error) bool { return internal.MessageOf(err) == msg }) {
+		return true
+	}
+	t.Error(failLogf(err, msgs, "Expected message '%v' was not present in actual error", msg))
+	return false
+}
+
+// RequireWrappedMessage asserts that err was created or wrapped with the message,
+// the test will terminate immediately if it wasn't.
+// See AssertWrappedMessage for more details
+func RequireWrappedMessage(t testing.TB, msg string, err error, msgs ...any) {
+	t.Helper()
+
+	if !AssertWrappedMessage(t, msg, err, msgs...) {
+		t.FailNow()
+	}
+}
+
+// AssertHasStackTrace asserts that an error in err has a stack trace
+//
+//	jtest.AssertHasStackTrace(t, err)
+func AssertHasStackTrace(t testing.TB, err error, msgs ...any) bool {
+	t.Helper()
+
+	if findError(err, func(err error) bool {
+		je, ok := err.(*internal.Error)
+		return ok && len(je.GetStackTrace()) > 0
+	}) {
+		return true
+	}
+	t.Error(failLogf(err, msgs, "Expected a stack trace in actual error"))
+	return false
+}
+
+// RequireHasStackTrace asserts that an error in err has a stack trace,
+// the test will terminate immediately if it doesn't.
+// See AssertHasStackTrace for more details
+func RequireHasStackTrace(t testing.TB, err error, msgs ...any) {
+	t.Helper()
+
+	if !AssertHasStackTrace(t, err, msgs...) {
+		t.FailNow()
+	}
+}
+
+// AssertSource asserts that an error in err was created or wrapped in the file,
+// which may be given with the leading elements of its path omitted,
+// or as a full source reference
+//
+//	jtest.AssertSource(t, "jettison/log/log.go", err)
+func AssertSource(t testing.TB, source string, err error, msgs ...any) bool {
+	t.Helper()
+
+	if findError(err, func(err error) bool {
+		je, ok := err.(*internal.Error)
+		return ok && matchSource(je.Source, source)
+	}) {
+		return true
+	}
+	t.Error(failLogf(err, msgs, "Expected source '%v' was not present in actual error", source))
+	return false
+}
+
+// RequireSource asserts that an error in err was created or wrapped in the file,
+// the test will terminate immediately if it wasn't.
+// See AssertSource for more details
+func RequireSource(t testing.TB, source string, err error, msgs ...any) {
+	t.Helper()
+
+	if !AssertSource(t, source, err, msgs...) {
+		t.FailNow()
+	}
+}
+
+// AssertJoinedCount asserts that err is made up of n errors,
+// i.e. it has n paths through its tree of joined errors
+//
+//	jtest.AssertJoinedCount(t, 2, err)
+func AssertJoinedCount(t testing.TB, n int, err error, msgs ...any) bool {
+	t.Helper()
+
+	var count int
+	if err != nil {
+		count = len(errors.Flatten(err))
+	}
+	if count != n {
+		t.Error(failLogf(err, msgs, "Expected %d joined errors, got %d", n, count))
+		return false
+	}
+	return true
+}
+
+// RequireJoinedCount asserts that err is made up of n errors,
+// the test will terminate immediately if it isn't.
+// See AssertJoinedCount for more details
+func RequireJoinedCount(t testing.TB, n int, err error, msgs ...any) {
+	t.Helper()
+
+	if !AssertJoinedCount(t, n, err, msgs...) {
+		t.FailNow()
+	}
+}
+
+// findError returns true if match returns true for any error in the tree of err
+func findError(err error, match func(error) bool) bool {
+	var found bool
+	errors.Walk(err, func(err error) bool {
+		found = match(err)
+		return !found
+	})
+	return found
+}
+
+// matchSource returns true if source is the reference or is in the file
+func matchSource(source, want string) bool {
+	if source == want {
+		return true
+	}
+	file := source
+	if i := strings.IndexAny(source, ": "); i >= 0 {
+		file = source[:i]
+	}
+	return file == want || strings.HasSuffix(file, "/"+want)
+}
+
+func failLogf(actual error, msgs []any, format string, args ...any) string {
+	l := fmt.Sprintf(format+":\n%+v", append(args, pretty(actual))...)
+
+	return l + messageFromMsgs(msgs...)
+}
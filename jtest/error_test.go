@@ -0,0 +1,97 @@
+package jtest
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+func TestErrorAssertions(t *testing.T) {
+	errCoded := errors.New("coded", j.C("ERR_48026e342952be11"))
+	joined := errors.Wrap(errors.Join(
+		errCoded,
+		fmt.Errorf("context: %w", io.EOF),
+	), "wrap")
+
+	testCases := []struct {
+		name   string
+		assert func(t testing.TB) bool
+		expMsg string
+	}{
+		{name: "code", assert: func(t testing.TB) bool {
+			return AssertCode(t, "ERR_48026e342952be11", joined)
+		}},
+		{name: "missing code", assert: func(t testing.TB) bool {
+			return AssertCode(t, "ERR_other", joined)
+		}, expMsg: "Expected error code 'ERR_other' was not present in actual error:"},
+		{name: "message isn't a code", assert: func(t testing.TB) bool {
+			return AssertCode(t, "not found", errors.New("not found"))
+		}, expMsg: "Expected error code 'not found' was not present in actual error:"},
+		{name: "nil code", assert: func(t testing.TB) bool {
+			return AssertCode(t, "ERR_48026e342952be11", nil)
+		}, expMsg: "Expected error code 'ERR_48026e342952be11' was not present in actual error:\n<nil>"},
+		{name: "wrapped message", assert: func(t testing.TB) bool {
+			return AssertWrappedMessage(t, "wrap", joined)
+		}},
+		{name: "inner message", assert: func(t testing.TB) bool {
+			return AssertWrappedMessage(t, "coded", joined)
+		}},
+		{name: "non-jettison message", assert: func(t testing.TB) bool {
+			return AssertWrappedMessage(t, "context", joined)
+		}},
+		{name: "full message", assert: func(t testing.TB) bool {
+			return AssertWrappedMessage(t, "wrap: coded", joined)
+		}, expMsg: "Expected message 'wrap: coded' was not present in actual error:"},
+		{name: "stack trace", assert: func(t testing.TB) bool {
+			return AssertHasStackTrace(t, errors.Wrap(io.EOF, "wrap"))
+		}},
+		{name: "no stack trace", assert: func(t testing.TB) bool {
+			return AssertHasStackTrace(t, errors.New("no trace", errors.WithoutStackTrace()))
+		}, expMsg: "Expected a stack trace in actual error:"},
+		{name: "non-jettison stack trace", assert: func(t testing.TB) bool {
+			return AssertHasStackTrace(t, io.EOF)
+		}, expMsg: "Expected a stack trace in actual error:\n- message: EOF\n"},
+		{name: "source file", assert: func(t testing.TB) bool {
+			return AssertSource(t, "error_test.go", joined)
+		}},
+		{name: "source path", assert: func(t testing.TB) bool {
+			return AssertSource(t, "jettison/jtest/error_test.go", joined)
+		}},
+		{name: "source partial element", assert: func(t testing.TB) bool {
+			return AssertSource(t, "r_test.go", joined)
+		}, expMsg: "Expected source 'r_test.go' was not present in actual error:"},
+		{name: "joined count", assert: func(t testing.TB) bool {
+			return AssertJoinedCount(t, 2, joined)
+		}},
+		{name: "nil joined count", assert: func(t testing.TB) bool {
+			return AssertJoinedCount(t, 0, nil)
+		}},
+		{name: "wrong joined count", assert: func(t testing.TB) bool {
+			return AssertJoinedCount(t, 1, joined)
+		}, expMsg: "Expected 1 joined errors, got 2:"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestErrorSpy(t)
+			pass := tc.assert(ts)
+			assert.Equal(t, tc.expMsg == "", pass)
+			assert.Equal(t, tc.expMsg != "", ts.failed)
+			if tc.expMsg != "" {
+				assert.Len(t, ts.messages, 1)
+				assert.Contains(t, ts.messages[0], tc.expMsg)
+			}
+		})
+	}
+}
+
+func TestAssertSourceFull(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	err := errors.New("test")
+	AssertSource(t, "error_test.go TestAssertSourceFull", err)
+	AssertSource(t, "error_test.go", err)
+}
@@ -0,0 +1,175 @@
+// Package golden compares errors and logs with golden files.
+//
+// It's separate from jtest because goldie registers the -update, -clean and
+// -template flags when it's imported, which would clash with test binaries
+// that define their own flags with those names.
+package golden
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/jtest"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/models"
+)
+
+// AssertError compares the error tree of err with the golden file testdata/<test name>.golden,
+// the test will be marked failed if they're different. Run the test with -update to write
+// the golden file.
+//
+// Sources and stack traces are written without line numbers or directories, like
+// errors.TestingConfig, so the golden file only changes when the error does.
+//
+//	golden.AssertError(t, err)
+func AssertError(t testing.TB, err error) {
+	t.Helper()
+
+	goldie.New(t).Assert(t, t.Name(), marshalGolden(t, goldenTree(err)))
+}
+
+// Logs records the entries which are logged, see CaptureLogs
+type Logs struct {
+	mu      sync.Mutex
+	entries []log.Entry
+}
+
+// CaptureLogs records the entries which are logged until the end of the test
+//
+//	logs := golden.CaptureLogs(t)
+//	handle(ctx, req)
+//	logs.Assert(t)
+func CaptureLogs(t testing.TB) *Logs {
+	var l Logs
+	log.SetLoggerForTesting(t, &l)
+	return &l
+}
+
+// Log implements log.Logger
+func (l *Logs) Log(_ context.Context, e log.Entry) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	return e.Message
+}
+
+// Entries returns the entries which have been logged
+func (l *Logs) Entries() []log.Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]log.Entry(nil), l.entries...)
+}
+
+// Assert compares the entries which have been logged with the golden file
+// testdata/<test name>.golden, in the same way as AssertError. Timestamps aren't written.
+func (l *Logs) Assert(t testing.TB) {
+	t.Helper()
+
+	entries := l.Entries()
+	golden := make([]goldenEntry, 0, len(entries))
+	for _, e := range entries {
+		golden = append(golden, goldenEntry{
+			Level:      e.Level,
+			Message:    e.Message,
+			Source:     normaliseRef(e.Source),
+			Parameters: e.Parameters,
+			Error:      goldenTree(e.Err),
+		})
+	}
+	goldie.New(t).Assert(t, t.Name(), marshalGolden(t, golden))
+}
+
+type goldenEntry struct {
+	Level      log.Level         `yaml:"level"`
+	Message    string            `yaml:"message,omitempty"`
+	Source     string            `yaml:"source,omitempty"`
+	Parameters []models.KeyValue `yaml:"parameters,omitempty"`
+	Error      *goldenError      `yaml:"error,omitempty"`
+}
+
+type goldenError struct {
+	Message    string            `yaml:"message,omitempty"`
+	Code       string            `yaml:"code,omitempty"`
+	Source     string            `yaml:"source,omitempty"`
+	KV         []models.KeyValue `yaml:"kv,omitempty"`
+	StackTrace []string          `yaml:"stack_trace,omitempty"`
+	// Wraps is the error which this one wraps
+	Wraps *goldenError `yaml:"wraps,omitempty"`
+	// Joins are the errors which this one joins
+	Joins []*goldenError `yaml:"joins,omitempty"`
+}
+
+func goldenTree(err error) *goldenError {
+	if err == nil {
+		return nil
+	}
+	var g goldenError
+	switch e := err.(type) {
+	case *internal.Error:
+		g.Message = e.Message
+		g.Code = e.Code
+		g.Source = normaliseRef(e.Source)
+		g.KV = e.KV
+		for _, line := range e.GetStackTrace() {
+			if !isStdLine(line) {
+				g.StackTrace = append(g.StackTrace, normaliseRef(line))
+			}
+		}
+		g.Wraps = goldenTree(e.Err)
+	case interface{ Unwrap() []error }:
+		for _, c := range e.Unwrap() {
+			if c != nil {
+				g.Joins = append(g.Joins, goldenTree(c))
+			}
+		}
+	default:
+		g.Message = internal.MessageOf(err)
+		if w, ok := err.(interface{ Unwrap() error }); ok {
+			g.Wraps = goldenTree(w.Unwrap())
+		}
+	}
+	return &g
+}
+
+// refLine matches the line number of a source reference or link
+var refLine = regexp.MustCompile(`(:|#L)\d+$`)
+
+// normaliseRef removes the directories and line number from the source
+// reference at the start of s, e.g. a source or stack trace line
+func normaliseRef(s string) string {
+	if s == "" {
+		return ""
+	}
+	ref, rest, hasRest := strings.Cut(s, " ")
+	ref = path.Base(refLine.ReplaceAllString(ref, ""))
+	if !hasRest {
+		return ref
+	}
+	return ref + " " + rest
+}
+
+// isStdLine returns true if the stack trace line is a call in the
+// runtime or testing packages, these change between Go versions
+func isStdLine(line string) bool {
+	return strings.HasPrefix(line, "runtime/") || strings.HasPrefix(line, "testing/")
+}
+
+func marshalGolden(t testing.TB, v any) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	jtest.RequireNil(t, enc.Encode(v))
+	jtest.RequireNil(t, enc.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,69 @@
+package golden
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+//go:generate go test . -run "TestAssertError|TestCaptureLogs" -update
+
+func TestAssertError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{name: "nil"},
+		{name: "non-jettison", err: fmt.Errorf("context: %w", io.EOF)},
+		{name: "jettison", err: errors.New("test error", j.C("ERR_48026e342952be11"), j.KV("key", "value"))},
+		{
+			name: "joined",
+			err: errors.Wrap(errors.Join(
+				errors.New("a", j.C("error_a")),
+				fmt.Errorf("context: %w", errors.Wrap(io.EOF, "b")),
+			), "wrap", j.KV("wrap", "true")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			AssertError(t, tc.err)
+		})
+	}
+}
+
+func TestCaptureLogs(t *testing.T) {
+	logs := CaptureLogs(t)
+
+	ctx := log.ContextWith(context.Background(), j.KV("ctx_key", "ctx_val"))
+	log.Info(ctx, "lookup account", j.KV("account_id", 123))
+	log.Error(ctx, errors.Wrap(io.EOF, "lookup account", j.C("ERR_48026e342952be11")))
+
+	assert.Len(t, logs.Entries(), 2)
+	logs.Assert(t)
+}
+
+func TestNormaliseRef(t *testing.T) {
+	testCases := []struct {
+		name string
+		ref  string
+		exp  string
+	}{
+		{name: "source", ref: "github.com/luno/jettison/jtest/golden/golden.go:12", exp: "golden.go"},
+		{name: "stack line", ref: "github.com/luno/jettison/jtest/golden/golden.go:12 AssertError", exp: "golden.go AssertError"},
+		{name: "link", ref: "https://github.com/luno/jettison/blob/main/jtest/golden/golden.go#L12 AssertError", exp: "golden.go AssertError"},
+		{name: "testing config", ref: "golden.go AssertError.func1", exp: "golden.go AssertError.func1"},
+		{name: "empty"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, normaliseRef(tc.ref))
+		})
+	}
+}
@@ -117,6 +117,8 @@ message:  errors in chain check
 }
 
 func TestPretty(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
 	tt := []struct {
 		name     string
 		err      error
@@ -134,12 +136,12 @@ func TestPretty(t *testing.T) {
 		{
 			name:     "jettison",
 			err:      errors.New("test error", j.C("ERR_48026e342952be11")),
-			expected: "- message: test error\n  code: ERR_48026e342952be11\n",
+			expected: "- message: test error\n  code: ERR_48026e342952be11\n  source: j_test.go TestPretty\n",
 		},
 		{
 			name:     "wrapped",
 			err:      errors.Wrap(io.ErrClosedPipe, "wrapping text"),
-			expected: "- message: 'wrapping text: io: read/write on closed pipe'\n",
+			expected: "- message: 'wrapping text: io: read/write on closed pipe'\n  source: j_test.go TestPretty\n",
 		},
 		{
 			name: "joined",
@@ -149,14 +151,40 @@ func TestPretty(t *testing.T) {
 			), "wrap", j.KV("wrap", "true")),
 			expected: `- message: a
   code: error_a
+  source: j_test.go TestPretty
   kv:
     - key: wrap
       value: "true"
+  wrapped:
+    - wrap
 - message: b
   code: error_b
+  source: j_test.go TestPretty
   kv:
     - key: wrap
       value: "true"
+  wrapped:
+    - wrap
+`,
+		},
+		{
+			name: "multi",
+			err: func() error {
+				var m errors.Multi
+				m.AddIf(true, "name is required", errors.Field("name"))
+				m.Add(io.EOF, errors.Field("body"))
+				return m.ErrOrNil()
+			}(),
+			expected: `- message: name is required
+  source: j_test.go TestPretty.func1
+  kv:
+    - key: field
+      value: name
+- message: EOF
+  source: j_test.go TestPretty.func1
+  kv:
+    - key: field
+      value: body
 `,
 		},
 	}
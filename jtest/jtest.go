@@ -3,6 +3,11 @@
 //
 // The style is similar to the assert and require packages of the
 // github.com/stretchr/testify library.
+//
+// Assertions which compare errors and logs with golden files are in the
+// jtest/golden package, see golden.AssertError and golden.CaptureLogs.
+// They're kept separate so that importing jtest doesn't register the
+// -update flag used to write golden files.
 package jtest
 
 import (
@@ -135,8 +140,10 @@ func failJKeyValuesMismatch(key, expected, actual string, msgs ...interface{}) s
 type prettyError struct {
 	Message string            `yaml:"message,omitempty"`
 	Code    string            `yaml:"code,omitempty"`
+	Source  string            `yaml:"source,omitempty"`
 	KV      []models.KeyValue `yaml:"kv,omitempty"`
-	// TODO(adam): Add source
+	// Wrapped are the messages of the errors which wrap a joined error, outermost first
+	Wrapped []string `yaml:"wrapped,omitempty"`
 }
 
 func pretty(err error) string {
@@ -154,7 +161,12 @@ func pretty(err error) string {
 			// Can use the fully wrapped message if the error isn't joined
 			pret.Message = p[0].Error()
 		}
-		for _, e := range p {
+		for i, e := range p {
+			if _, isJoin := e.(interface{ Unwrap() []error }); len(paths) > 1 && i < len(p)-1 && !isJoin {
+				if msg := internal.MessageOf(e); msg != "" {
+					pret.Wrapped = append(pret.Wrapped, msg)
+				}
+			}
 			je, ok := e.(*internal.Error)
 			if !ok {
 				continue
@@ -163,6 +175,9 @@ func pretty(err error) string {
 			if je.Code != "" {
 				pret.Code = je.Code
 			}
+			if je.Source != "" {
+				pret.Source = je.Source
+			}
 			pret.KV = append(pret.KV, je.KV...)
 		}
 		pretties = append(pretties, pret)
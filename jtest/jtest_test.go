@@ -2,6 +2,7 @@ package jtest
 
 import (
 	goerrors "errors"
+	"flag"
 	"strings"
 	"testing"
 
@@ -94,3 +95,13 @@ func Test_assertJettisonErrors(t *testing.T) {
 		})
 	}
 }
+
+// TestNoGlobalFlags checks that importing jtest doesn't register flags,
+// which would panic in tests that define flags with the same names
+func TestNoGlobalFlags(t *testing.T) {
+	for _, name := range []string{"update", "clean", "template"} {
+		if flag.Lookup(name) != nil {
+			t.Errorf("flag %q is registered", name)
+		}
+	}
+}
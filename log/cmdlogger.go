@@ -5,24 +5,78 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 
+	"github.com/luno/jettison/internal"
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
+type CmdOption func(*CmdLogger)
+
+// WithCmdColor enables or disables colour in the output,
+// by default colour is only used when writing to a terminal
+func WithCmdColor(enabled bool) CmdOption {
+	return func(c *CmdLogger) {
+		c.color = &enabled
+	}
+}
+
+// WithCmdStackTraceLines collapses each stack trace to its first n lines,
+// followed by the number of lines left out. Stack traces are hidden
+// when n is zero, by default they're written in full.
+func WithCmdStackTraceLines(n int) CmdOption {
+	return func(c *CmdLogger) {
+		c.stackLines = n
+	}
+}
+
+// WithCmdErrorTree writes logged errors as a tree, showing where they were
+// wrapped and joined, instead of a list of each error with its stack trace
+func WithCmdErrorTree(enabled bool) CmdOption {
+	return func(c *CmdLogger) {
+		c.errorTree = enabled
+	}
+}
+
 // NewCmdLogger returns a stdout human friendly command line logger.
-func NewCmdLogger(w io.Writer, stripTime bool) *CmdLogger {
-	return &CmdLogger{
-		logger:    log.New(w, "", 0),
-		stripTime: stripTime,
+func NewCmdLogger(w io.Writer, stripTime bool, ol ...CmdOption) *CmdLogger {
+	c := &CmdLogger{
+		logger:     log.New(w, "", 0),
+		stripTime:  stripTime,
+		stackLines: -1,
+		msgColor:   color.New(color.FgHiRed),
+		codeColor:  color.New(color.FgYellow),
+		metaColor:  color.New(color.Faint),
+	}
+	for _, o := range ol {
+		o(c)
+	}
+	for _, col := range []*color.Color{c.msgColor, c.codeColor, c.metaColor} {
+		switch {
+		case c.color != nil && *c.color:
+			col.EnableColor()
+		case c.color != nil || !isTerminal(w):
+			col.DisableColor()
+		}
 	}
+	return c
 }
 
 type CmdLogger struct {
-	logger    *log.Logger
-	stripTime bool
+	logger     *log.Logger
+	stripTime  bool
+	color      *bool
+	stackLines int
+	errorTree  bool
+
+	msgColor  *color.Color
+	codeColor *color.Color
+	metaColor *color.Color
 }
 
 func (c *CmdLogger) Log(_ context.Context, l Entry) string {
@@ -51,8 +105,12 @@ func (c *CmdLogger) Log(_ context.Context, l Entry) string {
 			conciseSource(l.Source),
 			parameterString(l.Parameters),
 		)
-		for _, err := range errs {
-			writeError(&sb, err)
+		if c.errorTree && l.Err != nil {
+			c.writeErrorTree(&sb, l.Err)
+		} else {
+			for _, err := range errs {
+				c.writeError(&sb, err)
+			}
 		}
 	}
 	c.logger.Print(sb.String())
@@ -93,14 +151,170 @@ func conciseSource(source string) string {
 	return strings.Join(res, "/")
 }
 
-func writeError(w io.Writer, err ErrorObject) {
+// writeError writes an error object, it's used when the entry
+// doesn't have the original error to write as a tree
+func (c *CmdLogger) writeError(w io.Writer, err ErrorObject) {
 	ps := parameterString(err.Parameters)
-	_, _ = fmt.Fprintf(w, " 🚨 %s%s", color.HiRedString(err.Message), ps)
+	_, _ = fmt.Fprintf(w, " 🚨 %s%s", c.msgColor.Sprint(err.Message), ps)
 	if len(err.StackTrace.Content()) == 0 {
 		_, _ = fmt.Fprint(w, "(error without stack trace)")
 	}
 	_, _ = fmt.Fprintln(w)
-	for _, line := range err.StackTrace.Content() {
-		_, _ = fmt.Fprintf(w, "  - %s\n", line)
+	c.writeStackTrace(w, "  ", err.StackTrace.Content())
+}
+
+// writeStackTrace writes the lines of a stack trace with the indent,
+// collapsing it to the configured number of lines
+func (c *CmdLogger) writeStackTrace(w io.Writer, indent string, lines []string) {
+	shown := lines
+	if c.stackLines >= 0 && len(lines) > c.stackLines {
+		shown = lines[:c.stackLines]
+	}
+	for _, line := range shown {
+		_, _ = fmt.Fprintf(w, "%s- %s\n", indent, line)
+	}
+	if n := len(lines) - len(shown); n > 0 {
+		_, _ = fmt.Fprintf(w, "%s%s\n", indent, c.metaColor.Sprintf("(%d stack lines hidden)", n))
 	}
 }
+
+// writeErrorTree writes err and the errors it wraps as a tree, e.g.
+//
+//	🚨 joined errors
+//	├── lookup alice (ERR_NOT_FOUND) [user=alice] @ users.go:12
+//	│      - users.go:12 lookup
+//	└── lookup bob (ERR_NOT_FOUND) [user=bob] @ users.go:12
+//	       - users.go:12 lookup
+//
+// Stack traces are merged with the traces above them in the tree, as they are
+// for ErrorObject, so they include the lines for hops between binaries and goroutines.
+func (c *CmdLogger) writeErrorTree(w io.Writer, err error) {
+	_, _ = fmt.Fprint(w, " 🚨 ")
+	c.writeErrorNode(w, err, " ", nil)
+}
+
+// writeErrorNode writes err and its children, outer is the
+// closest stack trace above err in the tree, if there is one
+func (c *CmdLogger) writeErrorNode(w io.Writer, err error, indent string, outer *trace.Section) {
+	n := makeErrorNode(err)
+	label := c.errorLabel(n)
+	if outer == nil && len(n.sections) == 0 && len(n.children) == 0 {
+		label += c.metaColor.Sprint("(error without stack trace)")
+	}
+	_, _ = fmt.Fprintln(w, label)
+
+	// Sections are written innermost first, as in a merged trace
+	var lines []string
+	for i := len(n.sections) - 1; i >= 0; i-- {
+		switch {
+		case i > 0:
+			lines = append(lines, trace.InnerTrace(n.sections[i-1], n.sections[i])...)
+		case outer != nil:
+			lines = append(lines, trace.InnerTrace(*outer, n.sections[i])...)
+		default:
+			lines = append(lines, n.sections[i].Trace...)
+		}
+	}
+	if len(n.sections) > 0 {
+		outer = &n.sections[len(n.sections)-1]
+	}
+
+	bar := " "
+	if len(n.children) > 0 {
+		bar = "│"
+	}
+	c.writeStackTrace(w, indent+bar+"  ", lines)
+
+	for i, child := range n.children {
+		branch, next := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, next = "└── ", "    "
+		}
+		_, _ = fmt.Fprint(w, indent+branch)
+		c.writeErrorNode(w, child, indent+next, outer)
+	}
+}
+
+func (c *CmdLogger) errorLabel(n errorNode) string {
+	var parts []string
+	switch {
+	case n.msg != "":
+		parts = append(parts, c.msgColor.Sprint(n.msg))
+	case len(n.children) > 1:
+		parts = append(parts, c.metaColor.Sprint("joined errors"))
+	default:
+		parts = append(parts, c.metaColor.Sprint("(no message)"))
+	}
+	if n.code != "" {
+		parts = append(parts, c.codeColor.Sprintf("(%s)", n.code))
+	}
+	if len(n.kvs) > 0 {
+		parts = append(parts, parameterString(n.kvs))
+	}
+	if n.source != "" {
+		parts = append(parts, c.metaColor.Sprint("@ "+n.source))
+	}
+	return strings.Join(parts, " ")
+}
+
+// errorNode is an error in the tree written by CmdLogger
+type errorNode struct {
+	msg    string
+	code   string
+	kvs    []models.KeyValue
+	source string
+	// sections are the stack traces of the jettison errors in the node, outermost first
+	sections []trace.Section
+	children []error
+}
+
+// makeErrorNode describes err and returns the errors it wraps. Jettison errors
+// without a message, which only add a code, key/values or a stack trace to the
+// error they wrap, are merged into the node of the wrapped error.
+func makeErrorNode(err error) errorNode {
+	var n errorNode
+	for {
+		switch e := err.(type) {
+		case *internal.Error:
+			if n.code == "" {
+				n.code = e.Code
+			}
+			if n.source == "" {
+				n.source = e.Source
+			}
+			if st := e.GetStackTrace(); len(st) > 0 {
+				n.sections = append(n.sections, trace.Section{
+					Binary:    e.Binary,
+					Trace:     st,
+					Goroutine: e.Trace.Goroutine(),
+				})
+			}
+			n.kvs = append(n.kvs, e.KV...)
+			if e.Message == "" && e.Err != nil {
+				err = e.Err
+				continue
+			}
+			n.msg = e.Message
+			if e.Err != nil {
+				n.children = []error{e.Err}
+			}
+		case interface{ Unwrap() []error }:
+			n.children = e.Unwrap()
+		case interface{ Unwrap() error }:
+			n.msg = err.Error()
+			if inner := e.Unwrap(); inner != nil {
+				// Wrapping errors usually include the message of the wrapped error
+				n.msg = strings.TrimSuffix(n.msg, ": "+inner.Error())
+				n.children = []error{inner}
+			}
+		default:
+			n.msg = err.Error()
+		}
+		return n
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
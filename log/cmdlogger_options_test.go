@@ -0,0 +1,102 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+//go:generate go test . -run TestCmdLoggerErrorTree -update
+
+func TestCmdLoggerErrorTree(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetCmdLoggerForTesting(t, &buf, log.WithCmdErrorTree(true))
+	cfg := errors.TestingConfig
+	cfg.TrackGoroutines = true
+	errors.SetTraceConfigTesting(t, cfg)
+
+	ctx := context.Background()
+	log.Error(ctx, io.EOF)
+	log.Error(ctx, errors.New("example error", j.KV("error_key", "error_val")))
+
+	err := errors.Wrap(stderrors.Join(
+		errors.New("not found", errors.WithCode("ERR_NOT_FOUND"), j.KV("user", "alice")),
+		fmt.Errorf("lookup bob: %w", errors.Wrap(io.ErrClosedPipe, "read")),
+	), "lookup users", j.KV("count", 2))
+	log.Error(ctx, errors.Wrap(err, "handle request"))
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- treeWorker()
+	}()
+	log.Error(ctx, stderrors.Join(
+		errors.Wrap(<-errCh, "consumed"),
+		io.ErrUnexpectedEOF,
+	))
+
+	// Goroutine ids change between runs
+	out := regexp.MustCompile(`goroutine \d+`).ReplaceAll(buf.Bytes(), []byte("goroutine N"))
+	goldie.New(t).Assert(t, t.Name(), out)
+}
+
+func treeWorker() error {
+	return errors.New("from worker")
+}
+
+//go:generate go test . -run TestCmdLoggerStackTraceLines -update
+
+func TestCmdLoggerStackTraceLines(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	err := stackTraceLinesErr()
+
+	testCases := []struct {
+		name  string
+		lines int
+	}{
+		{name: "hidden", lines: 0},
+		{name: "collapsed", lines: 1},
+		{name: "full", lines: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetCmdLoggerForTesting(t, &buf, log.WithCmdStackTraceLines(tc.lines))
+			log.Error(context.Background(), err)
+			goldie.New(t).Assert(t, t.Name(), buf.Bytes())
+		})
+	}
+}
+
+func stackTraceLinesErr() error {
+	return errors.Wrap(nestedErr(), "outer")
+}
+
+func nestedErr() error {
+	return errors.New("inner", errors.WithCode("ERR_INNER"))
+}
+
+func TestCmdLoggerColor(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	err := errors.New("coloured", errors.WithCode("ERR_COLOUR"))
+
+	var buf bytes.Buffer
+	log.SetCmdLoggerForTesting(t, &buf, log.WithCmdColor(true))
+	log.Error(context.Background(), err)
+	assert.Contains(t, buf.String(), "\x1b[")
+
+	buf.Reset()
+	log.SetCmdLoggerForTesting(t, &buf)
+	log.Error(context.Background(), err)
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
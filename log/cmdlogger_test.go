@@ -32,5 +32,10 @@ func TestCmdLogger(t *testing.T) {
 	)
 	log.Error(ctx, err)
 
+	var m errors.Multi
+	m.AddIf(true, "name is required", errors.Field("name"))
+	m.Add(io.ErrUnexpectedEOF, errors.Field("body"))
+	log.Error(ctx, m.ErrOrNil())
+
 	goldie.New(t).Assert(t, "cmd_logger", buf.Bytes())
 }
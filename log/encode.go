@@ -0,0 +1,269 @@
+package log
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/luno/jettison/models"
+)
+
+// maxPooledBuffer is the largest buffer that will be returned to the pool,
+// so that an occasional large log doesn't hold on to lots of memory
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// appendEntry appends the JSON encoding of e to dst using the schema.
+// This is equivalent to marshalling the object created by the schema
+// but avoids the allocations of building the object and of encoding/json.
+func (s Schema) appendEntry(dst []byte, e Entry) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	first := true
+	key := func(k string) {
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst = appendString(dst, k)
+		dst = append(dst, ':')
+	}
+
+	if s.MessageKey != "" {
+		key(s.MessageKey)
+		dst = appendString(dst, e.Message)
+	}
+	if s.SourceKey != "" {
+		key(s.SourceKey)
+		if s.FormatSource != nil {
+			dst, err = appendValue(dst, s.FormatSource(e.Source))
+		} else {
+			dst = appendString(dst, e.Source)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.LevelKey != "" {
+		key(s.LevelKey)
+		if s.FormatLevel != nil {
+			dst, err = appendValue(dst, s.FormatLevel(e.Level))
+		} else {
+			dst = appendString(dst, string(e.Level))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.TimestampKey != "" {
+		key(s.TimestampKey)
+		dst = appendTime(dst, e.Timestamp)
+	}
+	if len(e.Parameters) > 0 {
+		switch {
+		case !s.FlattenParameters:
+			if s.ParametersKey != "" {
+				key(s.ParametersKey)
+				dst = appendKeyValues(dst, e.Parameters)
+			}
+		case s.ParametersKey != "":
+			key(s.ParametersKey)
+			dst = append(dst, '{')
+			dst, _ = appendParameterFields(dst, e.Parameters, nil, true)
+			dst = append(dst, '}')
+		default:
+			dst, first = appendParameterFields(dst, e.Parameters, s.isKey, first)
+		}
+	}
+	if e.ErrorCode != nil && s.ErrorCodeKey != "" {
+		key(s.ErrorCodeKey)
+		dst = appendString(dst, *e.ErrorCode)
+	}
+	if e.ErrorObject != nil && s.ErrorKey != "" {
+		key(s.ErrorKey)
+		dst, err = appendValue(dst, s.formatError(*e.ErrorObject))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(e.ErrorObjects) > 0 && s.ErrorsKey != "" {
+		key(s.ErrorsKey)
+		dst = append(dst, '[')
+		for i, eo := range e.ErrorObjects {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst, err = appendValue(dst, s.formatError(eo))
+			if err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, '}'), nil
+}
+
+// appendParameterFields appends the parameters as the fields of an object, first is
+// true if there are no fields before them. Parameters with the same key as an earlier
+// one replace its value and those matching skip are left out.
+func appendParameterFields(dst []byte, params []models.KeyValue, skip func(string) bool, first bool) ([]byte, bool) {
+	for i, kv := range params {
+		if skip != nil && skip(kv.Key) {
+			continue
+		}
+		if replaced(params, i) {
+			continue
+		}
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst = appendString(dst, kv.Key)
+		dst = append(dst, ':')
+		dst = appendString(dst, lastValue(params, i))
+	}
+	return dst, first
+}
+
+// replaced returns true if the parameter at i has the same key as an earlier parameter,
+// in which case it has already been written with the latest value
+func replaced(params []models.KeyValue, i int) bool {
+	for _, kv := range params[:i] {
+		if kv.Key == params[i].Key {
+			return true
+		}
+	}
+	return false
+}
+
+func lastValue(params []models.KeyValue, i int) string {
+	v := params[i].Value
+	for _, kv := range params[i+1:] {
+		if kv.Key == params[i].Key {
+			v = kv.Value
+		}
+	}
+	return v
+}
+
+func appendKeyValues(dst []byte, kvs []models.KeyValue) []byte {
+	dst = append(dst, '[')
+	for i, kv := range kvs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"key":`...)
+		dst = appendString(dst, kv.Key)
+		dst = append(dst, `,"value":`...)
+		dst = appendString(dst, kv.Value)
+		dst = append(dst, '}')
+	}
+	return append(dst, ']')
+}
+
+func appendTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"')
+}
+
+func appendValue(dst []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return appendString(dst, v), nil
+	case Level:
+		return appendString(dst, string(v)), nil
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case time.Time:
+		return appendTime(dst, v), nil
+	case []models.KeyValue:
+		return appendKeyValues(dst, v), nil
+	case object:
+		return v.appendJSON(dst)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, b...), nil
+	}
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s as a JSON string, escaping it the same way as encoding/json
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				// Control characters and characters unsafe in HTML
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are line separators in JavaScript
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,177 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	stdlib_log "log"
+	"testing"
+	"time"
+
+	"github.com/go-stack/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/models"
+)
+
+func TestAppendString(t *testing.T) {
+	testCases := []string{
+		"",
+		"plain",
+		`"quoted" \ back\slash`,
+		"new\nline\ttab\rreturn\bback\fform",
+		"\x00\x01\x1f control",
+		"<html> & </html>",
+		"unicode ü 世界 🙂",
+		"invalid \xff\xfe utf8",
+		"separators \u2028 \u2029",
+	}
+	for _, s := range testCases {
+		exp, err := json.Marshal(s)
+		require.NoError(t, err)
+		assert.Equal(t, string(exp), string(appendString(nil, s)))
+	}
+}
+
+func TestAppendEntry(t *testing.T) {
+	code := "ERR_1"
+	testCases := []struct {
+		name  string
+		entry Entry
+	}{
+		{name: "empty"},
+		{
+			name: "info",
+			entry: Entry{
+				Message:    "message with \"quotes\"",
+				Source:     "github.com/luno/jettison/log/log.go:12",
+				Level:      LevelInfo,
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+				Parameters: []models.KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "<2>"}},
+			},
+		},
+		{
+			name: "error",
+			entry: Entry{
+				Message:     "error",
+				Level:       LevelError,
+				Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)),
+				ErrorCode:   &code,
+				ErrorObject: &ErrorObject{Code: code, Message: "error", StackTrace: MakeElastic([]string{"trace"})},
+			},
+		},
+		{
+			name: "joined errors",
+			entry: Entry{
+				Message:      "one\ntwo",
+				Level:        LevelError,
+				ErrorObjects: []ErrorObject{{Message: "one"}, {Message: "two"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := json.Marshal(tc.entry)
+			require.NoError(t, err)
+			act, err := DefaultSchema.appendEntry(nil, tc.entry)
+			require.NoError(t, err)
+			assert.Equal(t, string(exp), string(act))
+		})
+	}
+}
+
+func TestFlattenParameters(t *testing.T) {
+	e := Entry{
+		Message: "msg",
+		Parameters: []models.KeyValue{
+			{Key: "a", Value: "1"},
+			{Key: "message", Value: "clash"},
+			{Key: "b", Value: "2"},
+			{Key: "a", Value: "3"},
+		},
+	}
+	testCases := []struct {
+		name   string
+		schema Schema
+		exp    string
+	}{
+		{
+			name:   "top level",
+			schema: Schema{MessageKey: "message", FlattenParameters: true},
+			exp:    `{"message":"msg","a":"3","b":"2"}`,
+		},
+		{
+			name:   "only parameters",
+			schema: Schema{FlattenParameters: true},
+			exp:    `{"a":"3","message":"clash","b":"2"}`,
+		},
+		{
+			name:   "in object",
+			schema: Schema{MessageKey: "message", ParametersKey: "labels", FlattenParameters: true},
+			exp:    `{"message":"msg","labels":{"a":"3","message":"clash","b":"2"}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			act, err := tc.schema.appendEntry(nil, e)
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, string(act))
+		})
+	}
+}
+
+func benchEntry() Entry {
+	return Entry{
+		Message:   "test message",
+		Source:    "github.com/luno/jettison/log/encode_test.go:12",
+		Level:     LevelInfo,
+		Timestamp: time.Now(),
+		Parameters: []models.KeyValue{
+			{Key: "key1", Value: "v1"},
+			{Key: "key2", Value: "v2"},
+			{Key: "key3", Value: "v3"},
+		},
+	}
+}
+
+// BenchmarkJSONLogger benchmarks writing an entry with the JSON logger
+func BenchmarkJSONLogger(b *testing.B) {
+	l := newJSONLogger(io.Discard)
+	e := benchEntry()
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Log(nil, e)
+	}
+}
+
+// BenchmarkJSONLoggerOld benchmarks writing an entry using encoding/json,
+// as the JSON logger did before it had its own encoder
+func BenchmarkJSONLoggerOld(b *testing.B) {
+	l := stdlib_log.New(io.Discard, "", 0)
+	e := benchEntry()
+	b.ReportAllocs()
+	for b.Loop() {
+		res, err := json.Marshal(e)
+		if err != nil {
+			b.Fatal(err)
+		}
+		l.Print(string(res))
+	}
+}
+
+// BenchmarkCallerSource benchmarks getting the source of a log call
+func BenchmarkCallerSource(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = callerSource(0)
+	}
+}
+
+// BenchmarkCallerSourceOld benchmarks formatting the source of a log call
+func BenchmarkCallerSourceOld(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = fmt.Sprintf("%+v", stack.Caller(0))
+	}
+}
@@ -3,13 +3,17 @@ package log
 import (
 	"context"
 	"fmt"
-	"sort"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-stack/stack"
 
 	"github.com/luno/jettison/errors"
 	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/models"
 	"github.com/luno/jettison/trace"
 )
 
@@ -44,6 +48,7 @@ func WithError(err error) Option {
 		if len(codes) > 0 {
 			e.ErrorCode = &codes[0]
 		}
+		e.Err = err
 		addErrors(e, err)
 	})
 }
@@ -78,14 +83,18 @@ func Error(ctx context.Context, err error, opts ...Option) {
 
 func makeEntry(ctx context.Context, msg string, lvl Level, opts ...Option) Entry {
 	l := newEntry(msg, lvl, 3)
+	if len(opts) > 0 {
+		// Most options add a parameter, allocate for them up front
+		l.Parameters = make([]models.KeyValue, 0, len(opts))
+	}
 	for _, o := range opts {
 		o.ApplyToLog(&l)
 	}
 	l.Parameters = append(l.Parameters, ContextKeyValues(ctx)...)
 
 	// Sort the parameters for consistent logging.
-	sort.Slice(l.Parameters, func(i, j int) bool {
-		return l.Parameters[i].Key < l.Parameters[j].Key
+	slices.SortFunc(l.Parameters, func(a, b models.KeyValue) int {
+		return strings.Compare(a.Key, b.Key)
 	})
 
 	return l
@@ -143,11 +152,18 @@ func errorEntry(errPath []error) ErrorObject {
 			e.Stack = append(e.Stack, je.Binary)
 		}
 		e.Parameters = append(e.Parameters, je.KV...)
-		if len(je.StackTrace) > 0 {
-			m.Add(je.StackTrace, je.Binary)
+		if st := je.GetStackTrace(); len(st) > 0 {
+			m.AddSection(trace.Section{
+				Binary:    je.Binary,
+				Trace:     st,
+				Frames:    je.GetFrames(),
+				Goroutine: je.Trace.Goroutine(),
+			})
 		}
 	}
 	e.StackTrace = MakeElastic(m.FullTrace())
+	e.Frames = m.FullFrames()
+	e.Fingerprint = errors.FingerprintPath(errPath, errors.IgnoreLineNumbers())
 	return e
 }
 
@@ -157,12 +173,42 @@ func errorEntry(errPath []error) ErrorObject {
 func newEntry(msg string, level Level, stackSkip int) Entry {
 	return Entry{
 		Message:   msg,
-		Source:    fmt.Sprintf("%+v", stack.Caller(stackSkip)),
+		Source:    callerSource(stackSkip),
 		Level:     level,
 		Timestamp: time.Now(),
 	}
 }
 
+// sources caches the formatted source of each call site by program counter,
+// formatting sources is relatively expensive and allocates.
+var sources struct {
+	sync.RWMutex
+	m map[uintptr]string
+}
+
+// callerSource returns the source reference of the calling code, skipping `skip`
+// frames in the stack, formatted like `fmt.Sprintf("%+v", stack.Caller(skip))`
+func callerSource(skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return ""
+	}
+	sources.RLock()
+	src, ok := sources.m[pcs[0]]
+	sources.RUnlock()
+	if ok {
+		return src
+	}
+	src = fmt.Sprintf("%+v", stack.Caller(skip+1))
+	sources.Lock()
+	defer sources.Unlock()
+	if sources.m == nil {
+		sources.m = make(map[uintptr]string)
+	}
+	sources.m[pcs[0]] = src
+	return src
+}
+
 type Interface interface {
 	Debug(ctx context.Context, msg string, ol ...Option)
 	Info(ctx context.Context, msg string, ol ...Option)
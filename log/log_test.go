@@ -11,6 +11,7 @@ import (
 	"github.com/go-stack/stack"
 	"github.com/sebdah/goldie/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	jerrors "github.com/luno/jettison/errors"
 	"github.com/luno/jettison/internal"
@@ -53,6 +54,7 @@ func WithCustomTrace(bin string, stack []string) jerrors.ErrorOption {
 	return func(je *internal.Error) {
 		je.Binary = bin
 		je.StackTrace = stack
+		je.Trace = nil
 	}
 }
 
@@ -168,6 +170,37 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestSchemas(t *testing.T) {
+	jerrors.SetTraceConfigTesting(t, jerrors.TestingConfig)
+	schemas := map[string]Schema{
+		"ecs":     ECSSchema,
+		"gcp":     GCPSchema,
+		"datadog": DatadogSchema,
+	}
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			l := newJSONLogger(buf, source("service/handler.go:42"))
+			l.schema = schema
+			l.scrubTimestamp = true
+			SetLoggerForTesting(t, l)
+
+			ctx := ContextWith(context.Background(), kv("ctx_key", "ctx_val"))
+			Info(ctx, "test_message", kv("key", "value"), kv("message", "clash"))
+			Error(ctx, jerrors.New("test",
+				jerrors.WithCode("testcode"),
+				WithCustomTrace("testservice", []string{"teststacktrace"}),
+			))
+			Error(nil, jerrors.Join(
+				jerrors.New("one", WithCustomTrace("testservice", []string{"trace one"})),
+				jerrors.New("two", WithCustomTrace("testservice", []string{"trace two"})),
+			))
+
+			goldie.New(t).Assert(t, "schema_"+name, buf.Bytes())
+		})
+	}
+}
+
 func TestDeprecated(t *testing.T) {
 	opts := []Option{source("testsource")}
 
@@ -339,6 +372,7 @@ func TestAddError(t *testing.T) {
 			WithError(tc.err).ApplyToLog(&a)
 			// get rid of the other fields, tested separately
 			a = Entry{ErrorObject: a.ErrorObject, ErrorObjects: a.ErrorObjects}
+			clearFingerprints(&a)
 			assert.Equal(t, tc.expEntry, a)
 		})
 	}
@@ -397,7 +431,91 @@ func TestAddErrors(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			var e Entry
 			addErrors(&e, tc.err)
+			clearFingerprints(&e)
 			assert.Equal(t, tc.expEntry, e)
 		})
 	}
 }
+
+// clearFingerprints removes fingerprints from e, they're tested in TestAddErrorFingerprint
+func clearFingerprints(e *Entry) {
+	if e.ErrorObject != nil {
+		e.ErrorObject.Fingerprint = ""
+	}
+	for i := range e.ErrorObjects {
+		e.ErrorObjects[i].Fingerprint = ""
+	}
+}
+
+func TestAddErrorFingerprint(t *testing.T) {
+	jerrors.SetTraceConfigTesting(t, jerrors.TestingConfig)
+
+	err := jerrors.Wrap(jerrors.New("inner", jerrors.C("ERR_1")), "outer")
+	var e Entry
+	addErrors(&e, err)
+	require.NotNil(t, e.ErrorObject)
+	fp := e.ErrorObject.Fingerprint
+	assert.Equal(t, jerrors.Fingerprint(err, jerrors.IgnoreLineNumbers()), fp)
+
+	e = Entry{}
+	addErrors(&e, jerrors.Join(err, jerrors.New("other")))
+	require.Len(t, e.ErrorObjects, 2)
+	assert.Equal(t, fp, e.ErrorObjects[0].Fingerprint)
+	assert.NotEqual(t, e.ErrorObjects[0].Fingerprint, e.ErrorObjects[1].Fingerprint)
+}
+
+func TestAddErrorsFromGroup(t *testing.T) {
+	var g jerrors.Group
+	g.Go(func() error { return jerrors.New("one") }, kv("task", "one"))
+	g.Go(func() error { return jerrors.New("two") }, kv("task", "two"))
+
+	var e Entry
+	addErrors(&e, g.Wait())
+	require.Nil(t, e.ErrorObject)
+	require.Len(t, e.ErrorObjects, 2)
+
+	var msgs []string
+	for _, obj := range e.ErrorObjects {
+		msgs = append(msgs, obj.Message)
+		assert.Equal(t, []models.KeyValue{{Key: "task", Value: obj.Message}}, obj.Parameters)
+		assert.NotEmpty(t, obj.StackTrace)
+	}
+	assert.ElementsMatch(t, []string{"one", "two"}, msgs)
+}
+
+func TestAddErrorGoroutineBoundary(t *testing.T) {
+	cfg := jerrors.TestingConfig
+	cfg.TrackGoroutines = true
+	jerrors.SetTraceConfigTesting(t, cfg)
+
+	errCh := make(chan error)
+	go produceError(errCh)
+	err := jerrors.Wrap(<-errCh, "consumed")
+
+	var e Entry
+	addErrors(&e, err)
+	require.NotNil(t, e.ErrorObject)
+
+	st := e.ErrorObject.StackTrace.Content()
+	require.Len(t, st, 3)
+	assert.Equal(t, "log_test.go produceError", st[0])
+	assert.Equal(t, "log_test.go TestAddErrorGoroutineBoundary", st[2])
+	assert.Regexp(t, `^goroutine \d+ -> goroutine \d+$`, st[1])
+}
+
+func produceError(errCh chan<- error) {
+	errCh <- jerrors.New("from worker")
+}
+
+func TestAddErrorFrames(t *testing.T) {
+	cfg := jerrors.TestingConfig
+	cfg.IncludeFrames = true
+	jerrors.SetTraceConfigTesting(t, cfg)
+
+	var e Entry
+	addErrors(&e, jerrors.New("with frames"))
+	require.NotNil(t, e.ErrorObject)
+	require.Len(t, e.ErrorObject.Frames, 1)
+	assert.Equal(t, "TestAddErrorFrames", e.ErrorObject.Frames[0].Function)
+	assert.Equal(t, "github.com/luno/jettison/log/log_test.go", e.ErrorObject.Frames[0].File)
+}
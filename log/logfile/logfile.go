@@ -0,0 +1,387 @@
+// Package logfile provides an io.Writer which writes logs to a file,
+// rotating the file when it gets too large or too old.
+//
+// It can be used with any of the loggers in jettison/log:
+//
+//	w, err := logfile.Open("/var/log/tool/tool.log", logfile.MaxSize(100<<20), logfile.MaxBackups(5))
+//	if err != nil {
+//	  return err
+//	}
+//	defer w.Close()
+//	log.SetLogger(log.NewJSONLogger(w, log.DefaultSchema))
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+)
+
+// backupTimeFormat is used in the names of backups, it sorts in time order
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type Option func(*Writer)
+
+// MaxSize rotates the file before a write would make it larger than n bytes
+func MaxSize(n int64) Option {
+	return func(w *Writer) {
+		w.maxSize = n
+	}
+}
+
+// MaxAge rotates the file when it was opened more than d ago
+func MaxAge(d time.Duration) Option {
+	return func(w *Writer) {
+		w.maxAge = d
+	}
+}
+
+// MaxBackups limits the number of rotated files that are kept,
+// the oldest are deleted first. By default, all backups are kept.
+func MaxBackups(n int) Option {
+	return func(w *Writer) {
+		w.maxBackups = n
+	}
+}
+
+// ReopenOn makes the Writer reopen its file when the process receives one of
+// the signals, e.g. syscall.SIGHUP. This allows tools like logrotate to move
+// the file, after which the Writer creates a new one.
+// By default no signals are handled, as handling a signal changes how the whole
+// process responds to it, e.g. SIGHUP no longer terminates it. Programs which
+// handle signals themselves can call Writer.Reopen instead.
+func ReopenOn(sigs ...os.Signal) Option {
+	return func(w *Writer) {
+		w.signals = sigs
+	}
+}
+
+// Writer writes to a file, rotating it according to its options.
+// Rotated files are renamed with the time of the rotation, e.g. tool-2006-01-02T15-04-05.000.log,
+// and then compressed with gzip in the background.
+// Writer is safe for concurrent use.
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	signals    []os.Signal
+	now        func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	sigCh     chan os.Signal
+	millCh    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Open opens the file at path for appending, creating it and its directory if needed
+func Open(path string, ol ...Option) (*Writer, error) {
+	w := &Writer{
+		path:   path,
+		now:    time.Now,
+		millCh: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	for _, o := range ol {
+		o(w)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, errors.Wrap(err, "create log directory")
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.mill()
+	if len(w.signals) > 0 {
+		w.sigCh = make(chan os.Signal, 1)
+		signal.Notify(w.sigCh, w.signals...)
+		w.wg.Add(1)
+		go w.handleSignals()
+	}
+	// Tidy up any backups left from a previous run
+	w.triggerMill()
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, errors.New("log file is closed")
+	}
+	if w.shouldRotate(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		return n, errors.Wrap(err, "write log file")
+	}
+	return n, nil
+}
+
+func (w *Writer) shouldRotate(n int) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.maxSize > 0 && w.size+int64(n) > w.maxSize {
+		return true
+	}
+	return w.maxAge > 0 && w.now().Sub(w.openedAt) >= w.maxAge
+}
+
+// Rotate renames the current file to a backup and opens a new one
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errors.New("log file is closed")
+	}
+	return w.rotate()
+}
+
+// Reopen closes the file and opens it again, creating it if it has been moved,
+// e.g. by logrotate. See ReopenOn to do this when the process receives a signal.
+// The current file is kept when the new one can't be opened.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errors.New("log file is closed")
+	}
+	return w.reopen()
+}
+
+// Close closes the file and waits for any backups to be compressed
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		if w.sigCh != nil {
+			signal.Stop(w.sigCh)
+		}
+		close(w.done)
+	})
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return errors.Wrap(err, "close log file")
+	}
+	return nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "open log file", j.KV("path", w.path))
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "stat log file", j.KV("path", w.path))
+	}
+	w.file = f
+	w.size = info.Size()
+	w.openedAt = w.now()
+	return nil
+}
+
+// reopen opens the file at path and only then closes the previous one,
+// so that the Writer still has a file to write to if opening fails
+func (w *Writer) reopen() error {
+	old := w.file
+	if err := w.open(); err != nil {
+		return err
+	}
+	if err := old.Close(); err != nil {
+		return errors.Wrap(err, "close log file")
+	}
+	return nil
+}
+
+func (w *Writer) rotate() error {
+	backup := w.backupName(w.now())
+	if err := os.Rename(w.path, backup); err != nil {
+		return errors.Wrap(err, "rename log file")
+	}
+	if err := w.reopen(); err != nil {
+		// Put the file back so that we carry on writing to it
+		_ = os.Rename(backup, w.path)
+		return err
+	}
+	w.triggerMill()
+	return nil
+}
+
+// backupName returns the name of a backup rotated at t,
+// the time is added between the name and extension of the file.
+// If a backup already exists for t, the time is moved forward until
+// the name is unused, so backups are never overwritten and still sort in order.
+func (w *Writer) backupName(t time.Time) string {
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext) + "-"
+	for {
+		name := base + t.UTC().Format(backupTimeFormat) + ext
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// backups returns the names of all backups, oldest first
+func (w *Writer) backups() ([]string, error) {
+	dir := filepath.Dir(w.path)
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read log directory")
+	}
+	var ret []string
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".gz")
+		ts, ok := strings.CutSuffix(name, ext)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, ts); err != nil {
+			continue
+		}
+		ret = append(ret, filepath.Join(dir, e.Name()))
+	}
+	slices.Sort(ret)
+	return ret, nil
+}
+
+func (w *Writer) triggerMill() {
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+// mill compresses and removes backups in the background
+func (w *Writer) mill() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.millCh:
+			if err := w.millOnce(); err != nil {
+				_, _ = io.WriteString(os.Stderr, "jettison/log/logfile: "+err.Error()+"\n")
+			}
+		case <-w.done:
+			// Finish any work triggered before closing
+			select {
+			case <-w.millCh:
+				_ = w.millOnce()
+			default:
+			}
+			return
+		}
+	}
+}
+
+func (w *Writer) millOnce() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	if w.maxBackups > 0 && len(backups) > w.maxBackups {
+		for _, b := range backups[:len(backups)-w.maxBackups] {
+			if err := os.Remove(b); err != nil {
+				return errors.Wrap(err, "remove log backup")
+			}
+		}
+		backups = backups[len(backups)-w.maxBackups:]
+	}
+	for _, b := range backups {
+		if strings.HasSuffix(b, ".gz") {
+			continue
+		}
+		if err := compress(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compress writes a gzipped copy of the file at path and removes the original
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "open log backup")
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "create compressed log backup")
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Close()
+	} else {
+		_ = dst.Close()
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return errors.Wrap(err, "compress log backup")
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, "remove log backup")
+	}
+	return nil
+}
+
+func (w *Writer) handleSignals() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.sigCh:
+			if err := w.Reopen(); err != nil {
+				_, _ = io.WriteString(os.Stderr, "jettison/log/logfile: "+err.Error()+"\n")
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
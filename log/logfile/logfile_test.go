@@ -0,0 +1,186 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/log"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func open(t *testing.T, c *clock, ol ...Option) (*Writer, string) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := Open(path, ol...)
+	require.NoError(t, err)
+	w.now = c.now
+	w.openedAt = c.now()
+	t.Cleanup(func() { _ = w.Close() })
+	return w, path
+}
+
+func write(t *testing.T, w io.Writer, s string) {
+	_, err := io.WriteString(w, s)
+	require.NoError(t, err)
+}
+
+func readFile(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func readBackups(t *testing.T, w *Writer) []string {
+	backups, err := w.backups()
+	require.NoError(t, err)
+	var ret []string
+	for _, b := range backups {
+		require.True(t, strings.HasSuffix(b, ".log.gz"), b)
+		f, err := os.Open(b)
+		require.NoError(t, err)
+		zr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		ret = append(ret, string(content))
+	}
+	return ret
+}
+
+func TestRotateBySize(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, path := open(t, c, MaxSize(10))
+
+	write(t, w, "one\n")
+	write(t, w, "two\n")
+	c.t = c.t.Add(time.Second)
+	write(t, w, "three\n")
+	c.t = c.t.Add(time.Second)
+	write(t, w, "four\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "four\n", readFile(t, path))
+	assert.Equal(t, []string{"one\ntwo\n", "three\n"}, readBackups(t, w))
+	_, err := os.Stat(filepath.Join(filepath.Dir(path), "test-2024-01-02T03-04-06.000.log.gz"))
+	assert.NoError(t, err)
+}
+
+func TestRotateByAge(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, path := open(t, c, MaxAge(time.Hour))
+
+	write(t, w, "one\n")
+	c.t = c.t.Add(59 * time.Minute)
+	write(t, w, "two\n")
+	c.t = c.t.Add(time.Minute)
+	write(t, w, "three\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "three\n", readFile(t, path))
+	assert.Equal(t, []string{"one\ntwo\n"}, readBackups(t, w))
+}
+
+func TestMaxBackups(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, path := open(t, c, MaxBackups(2))
+
+	for _, s := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		write(t, w, s)
+		require.NoError(t, w.Rotate())
+		c.t = c.t.Add(time.Second)
+	}
+	write(t, w, "five\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "five\n", readFile(t, path))
+	assert.Equal(t, []string{"three\n", "four\n"}, readBackups(t, w))
+}
+
+func TestRotateSameTime(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, path := open(t, c)
+
+	for _, s := range []string{"one\n", "two\n", "three\n"} {
+		write(t, w, s)
+		require.NoError(t, w.Rotate())
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"one\n", "two\n", "three\n"}, readBackups(t, w))
+	_, err := os.Stat(filepath.Join(filepath.Dir(path), "test-2024-01-02T03-04-05.002.log.gz"))
+	assert.NoError(t, err)
+}
+
+func TestRotateFailure(t *testing.T) {
+	w, path := open(t, &clock{})
+	write(t, w, "one\n")
+
+	// Rotating fails when the file has been removed, but we keep the old handle
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, w.Rotate())
+	write(t, w, "two\n")
+}
+
+func TestReopenFailure(t *testing.T) {
+	w, path := open(t, &clock{})
+	write(t, w, "one\n")
+
+	// Something else in the way of the file means we can't open it
+	require.NoError(t, os.Rename(path, path+".old"))
+	require.NoError(t, os.Mkdir(path, 0o755))
+	assert.Error(t, w.Reopen())
+	write(t, w, "two\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "one\ntwo\n", readFile(t, path+".old"))
+}
+
+func TestNoSignalsByDefault(t *testing.T) {
+	w, _ := open(t, &clock{})
+	assert.Nil(t, w.sigCh)
+}
+
+func TestClosed(t *testing.T) {
+	w, _ := open(t, &clock{})
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	_, err := w.Write([]byte("closed\n"))
+	assert.Error(t, err)
+	assert.Error(t, w.Rotate())
+	assert.Error(t, w.Reopen())
+}
+
+func TestLoggers(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, path := open(t, c)
+
+	log.SetLoggerForTesting(t, log.NewJSONLogger(w, log.DefaultSchema))
+	log.Info(context.Background(), "json")
+	log.SetLoggerForTesting(t, log.NewCmdLogger(w, true))
+	log.Info(context.Background(), "cmd")
+	log.Print("deprecated")
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(readFile(t, path)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"message":"json"`)
+	assert.Contains(t, lines[1], "cmd")
+	assert.Contains(t, lines[2], "deprecated")
+}
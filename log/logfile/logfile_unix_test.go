@@ -0,0 +1,37 @@
+//go:build unix
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopenOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := Open(path, ReopenOn(syscall.SIGHUP))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	write(t, w, "before\n")
+	// Move the file like logrotate would
+	moved := path + ".1"
+	require.NoError(t, os.Rename(path, moved))
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	write(t, w, "after\n")
+	require.NoError(t, w.Close())
+	assert.Equal(t, "before\n", readFile(t, moved))
+	assert.Equal(t, "after\n", readFile(t, path))
+}
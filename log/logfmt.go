@@ -0,0 +1,130 @@
+package log
+
+import (
+	"context"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type LogfmtOption func(*LogfmtLogger)
+
+// WithLogfmtStackTraces includes the stack traces of errors as err.stack fields,
+// the lines of the stack trace are separated by escaped newlines
+func WithLogfmtStackTraces() LogfmtOption {
+	return func(l *LogfmtLogger) {
+		l.stackTraces = true
+	}
+}
+
+// NewLogfmtLogger returns a logger which writes entries to w in logfmt, e.g.
+//
+//	level=info ts=2024-01-02T03:04:05Z source=pkg/file.go:12 msg="hello world" key=value
+//
+// Errors are written as err.code, err.msg and err.source fields, when joined
+// errors are logged, the fields for each error are prefixed with err.0, err.1 and so on.
+func NewLogfmtLogger(w io.Writer, ol ...LogfmtOption) *LogfmtLogger {
+	l := &LogfmtLogger{logger: log.New(w, "", 0)}
+	for _, o := range ol {
+		o(l)
+	}
+	return l
+}
+
+func SetLogfmtLoggerForTesting(t testing.TB, w io.Writer, ol ...LogfmtOption) {
+	l := NewLogfmtLogger(w, ol...)
+	l.stripTime = true
+	SetLoggerForTesting(t, l)
+}
+
+type LogfmtLogger struct {
+	logger      *log.Logger
+	stackTraces bool
+	stripTime   bool
+}
+
+func (l *LogfmtLogger) Log(_ context.Context, e Entry) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	ts := e.Timestamp
+	if l.stripTime {
+		ts = time.Time{}
+	}
+	b := *buf
+	b = appendLogfmt(b, "level", string(e.Level))
+	b = append(b, " ts="...)
+	b = ts.UTC().AppendFormat(b, time.RFC3339Nano)
+	b = appendLogfmt(b, "source", e.Source)
+	b = appendLogfmt(b, "msg", e.Message)
+	for _, kv := range e.Parameters {
+		b = appendLogfmt(b, kv.Key, kv.Value)
+	}
+	if e.ErrorObject != nil {
+		b = l.appendError(b, "err.", *e.ErrorObject)
+	}
+	for i, eo := range e.ErrorObjects {
+		b = l.appendError(b, "err."+strconv.Itoa(i)+".", eo)
+	}
+	*buf = b
+
+	// Remove the leading space
+	res := string(b[1:])
+	_ = l.logger.Output(1, res)
+	return res
+}
+
+func (l *LogfmtLogger) appendError(b []byte, prefix string, eo ErrorObject) []byte {
+	if eo.Code != "" {
+		b = appendLogfmt(b, prefix+"code", eo.Code)
+	}
+	b = appendLogfmt(b, prefix+"msg", eo.Message)
+	if eo.Source != "" {
+		b = appendLogfmt(b, prefix+"source", eo.Source)
+	}
+	if st := eo.StackTrace.Content(); l.stackTraces && len(st) > 0 {
+		b = appendLogfmt(b, prefix+"stack", strings.Join(st, "\n"))
+	}
+	return b
+}
+
+// appendLogfmt appends a space and the key value pair to b,
+// the value is quoted if it contains spaces, quotes, equals signs
+// or characters which aren't printable
+func appendLogfmt(b []byte, key, value string) []byte {
+	b = append(b, ' ')
+	b = appendLogfmtKey(b, key)
+	b = append(b, '=')
+	if !needsQuotes(value) {
+		return append(b, value...)
+	}
+	return strconv.AppendQuote(b, value)
+}
+
+// appendLogfmtKey appends key, replacing any characters that aren't allowed in keys
+func appendLogfmtKey(b []byte, key string) []byte {
+	if key == "" {
+		return append(b, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		b = utf8.AppendRune(b, r)
+	}
+	return b
+}
+
+func needsQuotes(s string) bool {
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
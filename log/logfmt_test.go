@@ -0,0 +1,95 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/models"
+)
+
+func TestLogfmtLogger(t *testing.T) {
+	testCases := []struct {
+		name string
+		ol   []log.LogfmtOption
+	}{
+		{name: "logfmt"},
+		{name: "logfmt_stack_traces", ol: []log.LogfmtOption{log.WithLogfmtStackTraces()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetLogfmtLoggerForTesting(t, &buf, tc.ol...)
+			errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+			logExamples()
+
+			goldie.New(t).Assert(t, tc.name, buf.Bytes())
+		})
+	}
+}
+
+// logExamples is a named function so that it's included in stack traces
+func logExamples() {
+	ctx := log.ContextWith(context.TODO(), j.KS("ctx_key", "ctx_val"))
+	log.Info(ctx, "this is an info message", j.KS("quoted", `say "hi"`), j.KS("empty", ""))
+	log.Error(ctx, io.EOF)
+	log.Error(ctx, errors.New("example error", j.C("ERR_EXAMPLE"), errors.WithStackTrace()))
+	log.Error(ctx, stderrors.Join(
+		errors.New("error one"),
+		errors.New("error two"),
+	))
+}
+
+func TestLogfmtQuoting(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.NewLogfmtLogger(&buf)
+
+	testCases := []struct {
+		name  string
+		entry log.Entry
+		exp   string
+	}{
+		{
+			name:  "plain",
+			entry: log.Entry{Level: log.LevelInfo, Source: "file.go:1", Message: "hello"},
+			exp:   `level=info ts=0001-01-01T00:00:00Z source=file.go:1 msg=hello`,
+		},
+		{
+			name:  "spaces and quotes",
+			entry: log.Entry{Message: `a "quoted" message`},
+			exp:   `level= ts=0001-01-01T00:00:00Z source= msg="a \"quoted\" message"`,
+		},
+		{
+			name:  "newlines and equals",
+			entry: log.Entry{Message: "a=b\nc\\d"},
+			exp:   `level= ts=0001-01-01T00:00:00Z source= msg="a=b\nc\\d"`,
+		},
+		{
+			name:  "unicode",
+			entry: log.Entry{Message: "héllo wörld"},
+			exp:   `level= ts=0001-01-01T00:00:00Z source= msg="héllo wörld"`,
+		},
+		{
+			name:  "bad keys",
+			entry: log.Entry{Parameters: []models.KeyValue{{Key: "a b=c", Value: "d"}, {Key: "", Value: "e"}}},
+			exp:   `level= ts=0001-01-01T00:00:00Z source= msg= a_b_c=d _=e`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			res := l.Log(context.Background(), tc.entry)
+			assert.Equal(t, tc.exp, res)
+			assert.Equal(t, tc.exp+"\n", buf.String())
+		})
+	}
+}
@@ -2,7 +2,6 @@ package log
 
 import (
 	"context"
-	"encoding/json"
 	"io"
 	"log"
 	"os"
@@ -32,8 +31,8 @@ func SetLoggerForTesting(t testing.TB, l Logger) {
 	logger = l
 }
 
-func SetCmdLoggerForTesting(t testing.TB, w io.Writer) {
-	SetLoggerForTesting(t, NewCmdLogger(w, true))
+func SetCmdLoggerForTesting(t testing.TB, w io.Writer, ol ...CmdOption) {
+	SetLoggerForTesting(t, NewCmdLogger(w, true, ol...))
 }
 
 func SetDefaultLoggerForTesting(t testing.TB, w io.Writer, opts ...Option) {
@@ -43,37 +42,61 @@ func SetDefaultLoggerForTesting(t testing.TB, w io.Writer, opts ...Option) {
 	SetLoggerForTesting(t, l)
 }
 
-func newJSONLogger(w io.Writer, opts ...Option) *jsonLogger {
-	return &jsonLogger{
+// NewJSONLogger returns a logger which writes each entry to w as a line of JSON,
+// the fields of the JSON objects are set by schema, e.g. DefaultSchema or ECSSchema.
+func NewJSONLogger(w io.Writer, schema Schema) *JSONLogger {
+	l := newJSONLogger(w)
+	l.schema = schema
+	return l
+}
+
+func newJSONLogger(w io.Writer, opts ...Option) *JSONLogger {
+	return &JSONLogger{
 		logger: log.New(w, "", 0),
+		schema: DefaultSchema,
 		opts:   opts,
 	}
 }
 
-// jsonLogger is the default logger which writes json to stdout.
-type jsonLogger struct {
+// applyOptions is separate from Log so that the entry only escapes to the heap
+// when there are options to apply
+func applyOptions(l Entry, opts []Option) Entry {
+	for _, o := range opts {
+		o.ApplyToLog(&l)
+	}
+	return l
+}
+
+// JSONLogger writes entries as lines of JSON, see NewJSONLogger
+type JSONLogger struct {
 	logger *log.Logger
+	schema Schema
 
 	// default options and other flags for testing
 	opts           []Option
 	scrubTimestamp bool
 }
 
-func (jl *jsonLogger) Log(_ context.Context, l Entry) string {
-	for _, o := range jl.opts {
-		o.ApplyToLog(&l)
+func (jl *JSONLogger) Log(_ context.Context, l Entry) string {
+	if len(jl.opts) > 0 {
+		l = applyOptions(l, jl.opts)
 	}
 	if jl.scrubTimestamp {
 		l.Timestamp = time.Time{}
 	}
 
-	res, err := json.Marshal(l)
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var err error
+	*buf, err = jl.schema.appendEntry(*buf, l)
 	if err != nil {
 		jl.logger.Printf("jettison/log: failed to marshal log: %v", err)
 		jl.logger.Print(l.Message) // best-effort
 		return l.Message
 	}
 
-	jl.logger.Print(string(res))
-	return string(res)
+	res := string(*buf)
+	_ = jl.logger.Output(1, res)
+	return res
 }
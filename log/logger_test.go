@@ -2,6 +2,7 @@ package log_test
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,3 +43,12 @@ func toStr(l log.Entry) string {
 	}
 	return str
 }
+
+func BenchmarkInfo(b *testing.B) {
+	log.SetLoggerForTesting(b, log.NewJSONLogger(io.Discard, log.DefaultSchema))
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		log.Info(ctx, "message", j.KV("key", "value"), j.KV("count", 10), j.KV("ok", true))
+	}
+}
@@ -4,17 +4,21 @@ import (
 	"time"
 
 	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
 )
 
 type Level string
 
 type ErrorObject struct {
-	Code       string             `json:"code"`
-	Source     string             `json:"source"`
-	Message    string             `json:"message"`
-	Stack      []string           `json:"stack,omitempty"`
-	StackTrace ElasticStringArray `json:"stacktrace,omitempty"`
-	Parameters []models.KeyValue  `json:"parameters,omitempty"`
+	Code string `json:"code"`
+	// Fingerprint groups occurrences of the same error, see errors.Fingerprint
+	Fingerprint string             `json:"fingerprint,omitempty"`
+	Source      string             `json:"source"`
+	Message     string             `json:"message"`
+	Stack       []string           `json:"stack,omitempty"`
+	StackTrace  ElasticStringArray `json:"stacktrace,omitempty"`
+	Frames      []trace.Frame      `json:"frames,omitempty"`
+	Parameters  []models.KeyValue  `json:"parameters,omitempty"`
 }
 
 type Entry struct {
@@ -28,6 +32,10 @@ type Entry struct {
 
 	ErrorObject  *ErrorObject  `json:"error_object,omitempty"`
 	ErrorObjects []ErrorObject `json:"error_objects,omitempty"`
+
+	// Err is the error that was logged, it isn't serialised but
+	// allows loggers to inspect the original error tree
+	Err error `json:"-"`
 }
 
 // SetKey updates the list of parameters in the log with the given key/value pair.
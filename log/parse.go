@@ -0,0 +1,53 @@
+package log
+
+import (
+	"encoding/json"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+)
+
+// ParseEntry parses a line written by the JSON logger using DefaultSchema.
+// Err is rebuilt from the error objects of the entry, each object is the
+// flattened path to one of the errors that was logged, so they're joined
+// when there's more than one.
+func ParseEntry(b []byte) (Entry, error) {
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return Entry{}, errors.Wrap(err, "unmarshal log entry")
+	}
+	e.Err = entryError(e)
+	return e, nil
+}
+
+func entryError(e Entry) error {
+	objs := e.ErrorObjects
+	if e.ErrorObject != nil {
+		objs = append(objs, *e.ErrorObject)
+	}
+	errs := make([]error, 0, len(objs))
+	for _, obj := range objs {
+		var bin string
+		if len(obj.Stack) > 0 {
+			// The stack trace is merged from all the binaries, use the one that logged it
+			bin = obj.Stack[0]
+		}
+		errs = append(errs, &internal.Error{
+			Binary:     bin,
+			Message:    obj.Message,
+			Code:       obj.Code,
+			Source:     obj.Source,
+			StackTrace: obj.StackTrace.Content(),
+			Frames:     obj.Frames,
+			KV:         obj.Parameters,
+		})
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,60 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+)
+
+func TestParseEntry(t *testing.T) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+	var buf bytes.Buffer
+	log.SetDefaultLoggerForTesting(t, &buf)
+
+	log.Info(context.Background(), "hello", j.KV("key", "value"))
+	log.Error(context.Background(), errors.New("one", errors.WithCode("ERR_ONE")))
+	log.Error(context.Background(), errors.Join(
+		errors.New("one", errors.WithCode("ERR_ONE")),
+		errors.New("two", errors.WithCode("ERR_TWO")),
+	))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	info, err := log.ParseEntry(lines[0])
+	require.NoError(t, err)
+	assert.Equal(t, "hello", info.Message)
+	assert.Equal(t, log.LevelInfo, info.Level)
+	assert.Equal(t, []string{"key"}, keys(info))
+	assert.Nil(t, info.Err)
+
+	single, err := log.ParseEntry(lines[1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERR_ONE"}, errors.GetCodes(single.Err))
+	_, st, ok := errors.GetLastStackTrace(single.Err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"parse_test.go TestParseEntry"}, st)
+
+	joined, err := log.ParseEntry(lines[2])
+	require.NoError(t, err)
+	assert.Len(t, errors.Flatten(joined.Err), 2)
+	assert.ElementsMatch(t, []string{"ERR_ONE", "ERR_TWO"}, errors.GetCodes(joined.Err))
+
+	_, err = log.ParseEntry([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func keys(e log.Entry) []string {
+	var ret []string
+	for _, kv := range e.Parameters {
+		ret = append(ret, kv.Key)
+	}
+	return ret
+}
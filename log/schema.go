@@ -0,0 +1,207 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/luno/jettison/internal"
+)
+
+// Schema controls the shape of the JSON objects written by the JSON logger.
+// Each part of an Entry is written to the field with the configured key,
+// parts with an empty key are left out.
+type Schema struct {
+	MessageKey   string
+	SourceKey    string
+	LevelKey     string
+	TimestampKey string
+	ErrorCodeKey string
+	// ErrorKey is used when one error was logged and ErrorsKey when
+	// joined errors were logged, see Entry.ErrorObject and Entry.ErrorObjects
+	ErrorKey  string
+	ErrorsKey string
+
+	// ParametersKey is the key of the parameters field, by default the
+	// parameters are written as an array of key/value objects.
+	ParametersKey string
+	// FlattenParameters writes the parameters as fields of an object instead.
+	// If ParametersKey is empty, they're written as top-level fields,
+	// and parameters with the same key as another field are left out.
+	FlattenParameters bool
+
+	// FormatLevel converts the level before it's written, e.g. to upper case
+	FormatLevel func(Level) any
+	// FormatSource converts the source, a file reference like "pkg/file.go:12",
+	// before it's written
+	FormatSource func(string) any
+	// FormatError converts each error object before it's written
+	FormatError func(ErrorObject) any
+}
+
+// DefaultSchema is the schema used by the JSON logger unless another is given,
+// it writes the fields using the JSON tags of Entry.
+var DefaultSchema = Schema{
+	MessageKey:    "message",
+	SourceKey:     "source",
+	LevelKey:      "level",
+	TimestampKey:  "timestamp",
+	ParametersKey: "parameters",
+	ErrorCodeKey:  "error_code",
+	ErrorKey:      "error_object",
+	ErrorsKey:     "error_objects",
+}
+
+// ECSSchema writes entries using Elastic Common Schema fields.
+// Parameters are written as labels and the error code, message
+// and stack trace are written to the error field.
+// See https://www.elastic.co/guide/en/ecs/current/ecs-field-reference.html
+var ECSSchema = Schema{
+	TimestampKey:      "@timestamp",
+	LevelKey:          "log.level",
+	MessageKey:        "message",
+	SourceKey:         "log.origin",
+	ParametersKey:     "labels",
+	FlattenParameters: true,
+	ErrorKey:          "error",
+	ErrorsKey:         "errors",
+	FormatSource: func(src string) any {
+		file, line := splitSource(src)
+		return object{{"file", object{{"name", file}, {"line", line}}}}
+	},
+	FormatError: func(obj ErrorObject) any {
+		return nonEmpty(
+			field{"code", obj.Code},
+			field{"message", obj.Message},
+			field{"stack_trace", strings.Join(obj.StackTrace.Content(), "\n")},
+		)
+	},
+}
+
+// GCPSchema writes entries using the special fields of Google Cloud Logging.
+// Parameters are written as labels.
+// See https://cloud.google.com/logging/docs/structured-logging
+var GCPSchema = Schema{
+	MessageKey:        "message",
+	LevelKey:          "severity",
+	TimestampKey:      "timestamp",
+	SourceKey:         "logging.googleapis.com/sourceLocation",
+	ParametersKey:     "logging.googleapis.com/labels",
+	FlattenParameters: true,
+	ErrorCodeKey:      "error_code",
+	ErrorKey:          "error_object",
+	ErrorsKey:         "error_objects",
+	FormatLevel: func(l Level) any {
+		return strings.ToUpper(string(l))
+	},
+	FormatSource: func(src string) any {
+		file, line := splitSource(src)
+		// The line is an int64, which is encoded as a string in Cloud Logging's JSON
+		return object{{"file", file}, {"line", strconv.Itoa(line)}}
+	},
+}
+
+// DatadogSchema writes entries using Datadog's standard attributes.
+// Parameters are written as top-level attributes.
+// See https://docs.datadoghq.com/standard-attributes
+var DatadogSchema = Schema{
+	MessageKey:        "message",
+	LevelKey:          "status",
+	TimestampKey:      "timestamp",
+	SourceKey:         "logger",
+	FlattenParameters: true,
+	ErrorKey:          "error",
+	ErrorsKey:         "errors",
+	FormatSource: func(src string) any {
+		return object{{"name", src}}
+	},
+	FormatError: func(obj ErrorObject) any {
+		return nonEmpty(
+			field{"kind", obj.Code},
+			field{"message", obj.Message},
+			field{"stack", strings.Join(obj.StackTrace.Content(), "\n")},
+		)
+	},
+}
+
+// splitSource splits a source reference into the file and line number,
+// the line is zero if src doesn't end with one
+func splitSource(src string) (string, int) {
+	file, line, ok := internal.CutLast(src, ":")
+	if !ok {
+		return src, 0
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil {
+		return src, 0
+	}
+	return file, n
+}
+
+// nonEmpty returns an object of the fields which don't have empty string values
+func nonEmpty(fields ...field) object {
+	var obj object
+	for _, f := range fields {
+		if s, ok := f.value.(string); ok && s == "" {
+			continue
+		}
+		obj = append(obj, f)
+	}
+	return obj
+}
+
+// isKey returns true if key is used by one of the other fields in the schema
+func (s Schema) isKey(key string) bool {
+	switch key {
+	case s.MessageKey, s.SourceKey, s.LevelKey, s.TimestampKey,
+		s.ErrorCodeKey, s.ErrorKey, s.ErrorsKey:
+		return true
+	}
+	return false
+}
+
+func (s Schema) formatError(eo ErrorObject) any {
+	if s.FormatError != nil {
+		return s.FormatError(eo)
+	}
+	return eo
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+// object is a JSON object which keeps the order of its fields
+type object []field
+
+// set adds the field to o, replacing the value of an existing field with the same key
+func (o object) set(key string, value any) object {
+	for i, f := range o {
+		if f.key == key {
+			o[i].value = value
+			return o
+		}
+	}
+	return append(o, field{key, value})
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	return o.appendJSON(nil)
+}
+
+func (o object) appendJSON(dst []byte) ([]byte, error) {
+	dst = append(dst, '{')
+	for i, f := range o {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, f.key)
+		dst = append(dst, ':')
+		var err error
+		dst, err = appendValue(dst, f.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, '}'), nil
+}
@@ -0,0 +1,56 @@
+package sentry
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MaxBreadcrumbs is the number of recent logs kept for each context
+const MaxBreadcrumbs = 100
+
+type breadcrumbsKey struct{}
+
+type breadcrumbBuffer struct {
+	mu     sync.Mutex
+	crumbs []Breadcrumb
+}
+
+// WithBreadcrumbs returns a context which records the logs written with it.
+// Logs without errors are kept as breadcrumbs and attached to events for errors
+// logged later with the same context, e.g. for the duration of a request.
+// Only the latest MaxBreadcrumbs logs are kept.
+func WithBreadcrumbs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, breadcrumbsKey{}, &breadcrumbBuffer{})
+}
+
+func getBuffer(ctx context.Context) (*breadcrumbBuffer, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	buf, ok := ctx.Value(breadcrumbsKey{}).(*breadcrumbBuffer)
+	return buf, ok
+}
+
+func addBreadcrumb(ctx context.Context, b Breadcrumb) {
+	buf, ok := getBuffer(ctx)
+	if !ok {
+		return
+	}
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if len(buf.crumbs) >= MaxBreadcrumbs {
+		buf.crumbs = slices.Delete(buf.crumbs, 0, 1)
+	}
+	buf.crumbs = append(buf.crumbs, b)
+}
+
+func getBreadcrumbs(ctx context.Context) []Breadcrumb {
+	buf, ok := getBuffer(ctx)
+	if !ok {
+		return nil
+	}
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	return slices.Clone(buf.crumbs)
+}
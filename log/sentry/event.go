@@ -0,0 +1,198 @@
+package sentry
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/models"
+	"github.com/luno/jettison/trace"
+)
+
+// Event is a Sentry event, only the fields populated by this package are included.
+// See https://develop.sentry.dev/sdk/data-model/event-payloads/
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Exception   *Exceptions       `json:"exception,omitempty"`
+	Breadcrumbs *Breadcrumbs      `json:"breadcrumbs,omitempty"`
+}
+
+type Exceptions struct {
+	// Values are ordered from the innermost cause to the error that was logged
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+type Stacktrace struct {
+	// Frames are ordered from the outermost call to the innermost
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+}
+
+type Breadcrumbs struct {
+	Values []Breadcrumb `json:"values"`
+}
+
+type Breadcrumb struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Type      string            `json:"type,omitempty"`
+	Category  string            `json:"category,omitempty"`
+	Level     string            `json:"level,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+}
+
+// makeEvents converts e into one event for each error object in the entry,
+// errors joined together are reported as separate events.
+func makeEvents(e log.Entry) []Event {
+	objs := e.ErrorObjects
+	if e.ErrorObject != nil {
+		objs = []log.ErrorObject{*e.ErrorObject}
+	}
+	var paths [][]error
+	if e.Err != nil {
+		paths = errors.Flatten(e.Err)
+	}
+	events := make([]Event, 0, len(objs))
+	for i, obj := range objs {
+		ev := Event{
+			Timestamp: e.Timestamp,
+			Level:     string(e.Level),
+			Platform:  "go",
+			Logger:    e.Source,
+			Message:   e.Message,
+			Tags:      makeTags(e.Parameters, obj),
+		}
+		if obj.Fingerprint != "" {
+			ev.Fingerprint = []string{obj.Fingerprint}
+		}
+		var exc []Exception
+		if len(paths) == len(objs) {
+			exc = makeExceptions(paths[i])
+		}
+		if len(exc) == 0 {
+			exc = []Exception{objectException(obj)}
+		}
+		ev.Exception = &Exceptions{Values: exc}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func makeTags(params []models.KeyValue, obj log.ErrorObject) map[string]string {
+	tags := make(map[string]string, len(params)+2)
+	for _, kv := range params {
+		tags[kv.Key] = kv.Value
+	}
+	if obj.Code != "" {
+		tags["error_code"] = obj.Code
+	}
+	if obj.Source != "" {
+		tags["error_source"] = obj.Source
+	}
+	return tags
+}
+
+// makeExceptions creates an exception for each error in the path,
+// starting with the innermost error as Sentry expects
+func makeExceptions(path []error) []Exception {
+	var ret []Exception
+	for _, err := range path {
+		if _, isJoin := err.(interface{ Unwrap() []error }); isJoin {
+			continue
+		}
+		je, ok := err.(*internal.Error)
+		if !ok {
+			ret = append(ret, Exception{Type: fmt.Sprintf("%T", err), Value: err.Error()})
+			continue
+		}
+		exc := Exception{Type: je.Code, Value: je.Message}
+		if exc.Type == "" {
+			exc.Type = je.Message
+		}
+		if exc.Value == "" {
+			// Wrap can be called without a message, e.g. to add key values
+			continue
+		}
+		exc.Stacktrace = makeStacktrace(je.GetFrames(), je.GetStackTrace())
+		ret = append(ret, exc)
+	}
+	slices.Reverse(ret)
+	return ret
+}
+
+// objectException is used when the original error isn't available
+func objectException(obj log.ErrorObject) Exception {
+	exc := Exception{Type: obj.Code, Value: obj.Message}
+	if exc.Type == "" {
+		exc.Type = obj.Message
+	}
+	exc.Stacktrace = makeStacktrace(obj.Frames, obj.StackTrace.Content())
+	return exc
+}
+
+// makeStacktrace converts frames, or the formatted lines when there are no frames,
+// into a Sentry stack trace
+func makeStacktrace(frames []trace.Frame, lines []string) *Stacktrace {
+	var st Stacktrace
+	if len(frames) > 0 {
+		for _, f := range frames {
+			st.Frames = append(st.Frames, Frame{
+				Function: f.Function,
+				Module:   f.Package,
+				Filename: f.File,
+				Lineno:   f.Line,
+			})
+		}
+	} else {
+		for _, l := range lines {
+			st.Frames = append(st.Frames, Frame{Function: l})
+		}
+	}
+	if len(st.Frames) == 0 {
+		return nil
+	}
+	slices.Reverse(st.Frames)
+	return &st
+}
+
+func makeBreadcrumb(e log.Entry) Breadcrumb {
+	b := Breadcrumb{
+		Timestamp: e.Timestamp,
+		Type:      "default",
+		Category:  "log",
+		Level:     string(e.Level),
+		Message:   e.Message,
+	}
+	if len(e.Parameters) > 0 {
+		b.Data = make(map[string]string, len(e.Parameters))
+		for _, kv := range e.Parameters {
+			b.Data[kv.Key] = kv.Value
+		}
+	}
+	return b
+}
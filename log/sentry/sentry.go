@@ -0,0 +1,289 @@
+// Package sentry reports errors logged with jettison to Sentry.
+//
+// Logger wraps another log.Logger, every entry is passed on to it and
+// entries with errors are also sent to Sentry as events:
+//
+//	l, err := sentry.New(log.NewCmdLogger(os.Stderr, false), dsn)
+//	if err != nil {
+//	  return err
+//	}
+//	defer l.Close()
+//	log.SetLogger(l)
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/internal"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/trace"
+)
+
+const (
+	clientName = "jettison/1.0"
+
+	// DefaultQueueSize is the number of events which can be waiting to be sent
+	// before new events are dropped, see WithQueueSize
+	DefaultQueueSize = 100
+
+	// senders is the number of goroutines sending queued events to Sentry
+	senders = 4
+)
+
+type Option func(*Logger)
+
+// WithEnvironment sets the environment of events, e.g. production or staging
+func WithEnvironment(env string) Option {
+	return func(l *Logger) {
+		l.environment = env
+	}
+}
+
+// WithRelease overrides the release of events, which defaults to
+// the service and version from trace.CurrentIdentity
+func WithRelease(release string) Option {
+	return func(l *Logger) {
+		l.release = release
+	}
+}
+
+// WithHTTPClient sets the client used to send events to Sentry
+func WithHTTPClient(c *http.Client) Option {
+	return func(l *Logger) {
+		l.client = c
+	}
+}
+
+// WithQueueSize sets the number of events which can be waiting to be sent,
+// events logged while the queue is full are dropped
+func WithQueueSize(n int) Option {
+	return func(l *Logger) {
+		l.queueSize = n
+	}
+}
+
+// Logger is a log.Logger which sends errors to Sentry.
+//
+// Each ErrorObject in a log entry becomes an event, with an exception for each
+// error in the chain, tags from the error code and the log's key/values, and
+// breadcrumbs from earlier logs made with the same context, see WithBreadcrumbs.
+// Events are queued and sent in the background, call Flush to wait for them to be sent
+// and Close to stop sending events once the Logger is no longer used.
+// When Sentry can't keep up and the queue is full, new events are dropped rather than
+// blocking the caller.
+type Logger struct {
+	next   log.Logger
+	client *http.Client
+
+	endpoint string
+	auth     string
+	dsn      string
+
+	environment string
+	release     string
+	serverName  string
+
+	queueSize int
+	queue     chan Event
+	senders   sync.WaitGroup
+
+	mu sync.Mutex
+	// sent is signalled when pending drops to zero
+	sent    *sync.Cond
+	pending int
+	closed  bool
+}
+
+// New returns a Logger which reports errors to the Sentry project identified by dsn.
+// All entries are passed on to next, which can be nil to only report to Sentry.
+func New(next log.Logger, dsn string, ol ...Option) (*Logger, error) {
+	endpoint, key, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	id := trace.CurrentIdentity()
+	l := &Logger{
+		next:       next,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		endpoint:   endpoint,
+		auth:       fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, key),
+		dsn:        dsn,
+		release:    trace.Identity{Service: id.Service, Version: id.Version, Revision: id.Revision}.String(),
+		serverName: id.Host,
+		queueSize:  DefaultQueueSize,
+	}
+	for _, o := range ol {
+		o(l)
+	}
+	l.sent = sync.NewCond(&l.mu)
+	l.queue = make(chan Event, max(l.queueSize, 0))
+	l.senders.Add(senders)
+	for range senders {
+		go l.sendEvents()
+	}
+	return l, nil
+}
+
+// parseDSN returns the envelope endpoint and public key from a DSN
+// of the form {scheme}://{key}@{host}{path}/{project}
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", errors.Wrap(err, "invalid sentry dsn")
+	}
+	key := u.User.Username()
+	if key == "" {
+		return "", "", errors.New("sentry dsn missing public key")
+	}
+	path, project, _ := internal.CutLast(strings.TrimSuffix(u.Path, "/"), "/")
+	if project == "" {
+		return "", "", errors.New("sentry dsn missing project id")
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path + "/api/" + project + "/envelope/"}
+	return endpoint.String(), key, nil
+}
+
+func (l *Logger) Log(ctx context.Context, e log.Entry) string {
+	var res string
+	if l.next != nil {
+		res = l.next.Log(ctx, e)
+	}
+	if e.ErrorObject == nil && len(e.ErrorObjects) == 0 {
+		addBreadcrumb(ctx, makeBreadcrumb(e))
+		return res
+	}
+	crumbs := getBreadcrumbs(ctx)
+	for _, ev := range makeEvents(e) {
+		ev.EventID = newEventID()
+		ev.Release = l.release
+		ev.Environment = l.environment
+		ev.ServerName = l.serverName
+		if len(crumbs) > 0 {
+			ev.Breadcrumbs = &Breadcrumbs{Values: crumbs}
+		}
+		l.enqueue(ev)
+	}
+	return res
+}
+
+// Flush blocks until all queued events have been sent
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.pending > 0 {
+		l.sent.Wait()
+	}
+}
+
+// Close sends the queued events and stops the goroutines sending them,
+// events logged after Close are dropped
+func (l *Logger) Close() {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	l.senders.Wait()
+}
+
+func (l *Logger) enqueue(ev Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		stdlog.Printf("jettison/log/sentry: logger closed, dropping event %s", ev.EventID)
+		return
+	}
+	select {
+	case l.queue <- ev:
+		l.pending++
+	default:
+		stdlog.Printf("jettison/log/sentry: queue full, dropping event %s", ev.EventID)
+	}
+}
+
+func (l *Logger) sendEvents() {
+	defer l.senders.Done()
+	for ev := range l.queue {
+		err := l.send(ev)
+		if err != nil {
+			stdlog.Printf("jettison/log/sentry: failed to send event: %v", err)
+		}
+		l.mu.Lock()
+		l.pending--
+		if l.pending == 0 {
+			l.sent.Broadcast()
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *Logger) send(ev Event) error {
+	body, err := l.envelope(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create sentry request")
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", l.auth)
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send sentry request")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("unexpected response from sentry", j.KV("status", resp.Status))
+	}
+	return nil
+}
+
+// envelope encodes ev in the Sentry envelope format,
+// see https://develop.sentry.dev/sdk/data-model/envelopes/
+func (l *Logger) envelope(ev Event) ([]byte, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sentry event")
+	}
+	header, err := json.Marshal(map[string]any{
+		"event_id": ev.EventID,
+		"dsn":      l.dsn,
+		"sent_at":  time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sentry envelope")
+	}
+	item, err := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sentry envelope")
+	}
+	var buf bytes.Buffer
+	for _, b := range [][]byte{header, item, payload} {
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,306 @@
+package sentry_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luno/jettison/errors"
+	"github.com/luno/jettison/j"
+	"github.com/luno/jettison/log"
+	"github.com/luno/jettison/log/sentry"
+)
+
+// ingest is a stand-in for the Sentry envelope endpoint,
+// requests are checked in the handler and any problems are
+// reported from the test goroutine by received
+type ingest struct {
+	// block, when set, holds up requests until it's closed
+	block chan struct{}
+
+	mu     sync.Mutex
+	errs   []error
+	auth   []string
+	events []sentry.Event
+}
+
+func (i *ingest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if i.block != nil {
+		<-i.block
+	}
+	ev, err := readEnvelope(r)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err != nil {
+		i.errs = append(i.errs, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	i.auth = append(i.auth, r.Header.Get("X-Sentry-Auth"))
+	i.events = append(i.events, ev)
+}
+
+// received fails the test if any request was invalid and
+// returns the events which were sent
+func (i *ingest) received(t *testing.T) []sentry.Event {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, err := range i.errs {
+		t.Error(err)
+	}
+	return i.events
+}
+
+func readEnvelope(r *http.Request) (sentry.Event, error) {
+	if r.URL.Path != "/api/42/envelope/" {
+		return sentry.Event{}, fmt.Errorf("unexpected path %q", r.URL.Path)
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/x-sentry-envelope" {
+		return sentry.Event{}, fmt.Errorf("unexpected content type %q", ct)
+	}
+
+	sc := bufio.NewScanner(r.Body)
+	sc.Buffer(nil, 1<<20)
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return sentry.Event{}, err
+	}
+	if len(lines) != 3 {
+		return sentry.Event{}, fmt.Errorf("expected 3 lines in envelope, got %d", len(lines))
+	}
+
+	var header struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		return sentry.Event{}, fmt.Errorf("envelope header: %w", err)
+	}
+	var item struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &item); err != nil {
+		return sentry.Event{}, fmt.Errorf("item header: %w", err)
+	}
+	if item.Type != "event" || item.Length != len(lines[2]) {
+		return sentry.Event{}, fmt.Errorf("unexpected item header %q", lines[1])
+	}
+
+	var ev sentry.Event
+	if err := json.Unmarshal([]byte(lines[2]), &ev); err != nil {
+		return sentry.Event{}, fmt.Errorf("event: %w", err)
+	}
+	if ev.EventID != header.EventID {
+		return sentry.Event{}, fmt.Errorf("event id %q doesn't match envelope %q", ev.EventID, header.EventID)
+	}
+	return ev, nil
+}
+
+func setup(t *testing.T, ol ...sentry.Option) (*ingest, *sentry.Logger) {
+	return setupIngest(t, &ingest{}, ol...)
+}
+
+func setupIngest(t *testing.T, in *ingest, ol ...sentry.Option) (*ingest, *sentry.Logger) {
+	errors.SetTraceConfigTesting(t, errors.TestingConfig)
+
+	srv := httptest.NewServer(in)
+	t.Cleanup(srv.Close)
+
+	dsn := strings.Replace(srv.URL, "://", "://public@", 1) + "/42"
+	l, err := sentry.New(nil, dsn, ol...)
+	require.NoError(t, err)
+	t.Cleanup(l.Close)
+	log.SetLoggerForTesting(t, l)
+	return in, l
+}
+
+func TestLogError(t *testing.T) {
+	in, l := setup(t, sentry.WithEnvironment("test"), sentry.WithRelease("svc@v1.0.0"))
+	ctx := sentry.WithBreadcrumbs(context.Background())
+
+	log.Info(ctx, "handling request", j.KV("user", "alice"))
+	log.Info(context.Background(), "not in request")
+	log.Info(nil, "nil context")
+
+	err := errors.New("not found", errors.WithCode("ERR_NOT_FOUND"))
+	err = errors.Wrap(err, "lookup user", j.KV("user_id", 42))
+	log.Error(ctx, err)
+	l.Flush()
+
+	events := in.received(t)
+	require.Len(t, events, 1)
+	assert.Contains(t, in.auth[0], "sentry_key=public")
+
+	ev := events[0]
+	assert.Len(t, ev.EventID, 32)
+	assert.Equal(t, "error", ev.Level)
+	assert.Equal(t, "go", ev.Platform)
+	assert.Equal(t, "test", ev.Environment)
+	assert.Equal(t, "svc@v1.0.0", ev.Release)
+	assert.Equal(t, "lookup user: not found", ev.Message)
+	assert.Equal(t, []string{errors.Fingerprint(err, errors.IgnoreLineNumbers())}, ev.Fingerprint)
+	assert.Equal(t, map[string]string{
+		"error_code":   "ERR_NOT_FOUND",
+		"error_source": "sentry_test.go TestLogError",
+		"user_id":      "42",
+	}, ev.Tags)
+
+	require.NotNil(t, ev.Exception)
+	require.Len(t, ev.Exception.Values, 2)
+	inner, outer := ev.Exception.Values[0], ev.Exception.Values[1]
+	assert.Equal(t, "ERR_NOT_FOUND", inner.Type)
+	assert.Equal(t, "not found", inner.Value)
+	require.NotNil(t, inner.Stacktrace)
+	assert.Equal(t, []sentry.Frame{{Function: "sentry_test.go TestLogError"}}, inner.Stacktrace.Frames)
+	assert.Equal(t, "lookup user", outer.Type)
+	assert.Nil(t, outer.Stacktrace)
+
+	require.NotNil(t, ev.Breadcrumbs)
+	require.Len(t, ev.Breadcrumbs.Values, 1)
+	crumb := ev.Breadcrumbs.Values[0]
+	assert.Equal(t, "handling request", crumb.Message)
+	assert.Equal(t, "info", crumb.Level)
+	assert.Equal(t, map[string]string{"user": "alice"}, crumb.Data)
+}
+
+func TestLogJoinedErrors(t *testing.T) {
+	in, l := setup(t)
+
+	log.Error(context.Background(), errors.Join(
+		errors.New("one", errors.WithCode("ERR_ONE")),
+		errors.New("two", errors.WithCode("ERR_TWO")),
+	))
+	l.Flush()
+
+	events := in.received(t)
+	require.Len(t, events, 2)
+	var codes []string
+	for _, ev := range events {
+		assert.Nil(t, ev.Breadcrumbs)
+		require.Len(t, ev.Exception.Values, 1)
+		codes = append(codes, ev.Exception.Values[0].Type)
+	}
+	assert.ElementsMatch(t, []string{"ERR_ONE", "ERR_TWO"}, codes)
+}
+
+func TestLogFrames(t *testing.T) {
+	in, l := setup(t)
+	cfg := errors.TestingConfig
+	cfg.IncludeFrames = true
+	errors.SetTraceConfigTesting(t, cfg)
+
+	log.Error(context.Background(), errors.New("with frames"))
+	l.Flush()
+
+	events := in.received(t)
+	require.Len(t, events, 1)
+	st := events[0].Exception.Values[0].Stacktrace
+	require.NotNil(t, st)
+	require.Len(t, st.Frames, 1)
+	assert.Equal(t, "TestLogFrames", st.Frames[0].Function)
+	assert.Equal(t, "github.com/luno/jettison/log/sentry_test", st.Frames[0].Module)
+	assert.Equal(t, "github.com/luno/jettison/log/sentry/sentry_test.go", st.Frames[0].Filename)
+	assert.NotZero(t, st.Frames[0].Lineno)
+}
+
+func TestBreadcrumbLimit(t *testing.T) {
+	in, l := setup(t)
+	ctx := sentry.WithBreadcrumbs(context.Background())
+
+	for range sentry.MaxBreadcrumbs + 10 {
+		log.Info(ctx, "step")
+	}
+	log.Info(ctx, "last")
+	log.Error(ctx, errors.New("failed"))
+	l.Flush()
+
+	events := in.received(t)
+	require.Len(t, events, 1)
+	crumbs := events[0].Breadcrumbs.Values
+	require.Len(t, crumbs, sentry.MaxBreadcrumbs)
+	assert.Equal(t, "last", crumbs[len(crumbs)-1].Message)
+}
+
+func TestQueueFull(t *testing.T) {
+	in, l := setupIngest(t, &ingest{block: make(chan struct{})}, sentry.WithQueueSize(1))
+
+	const logged = 20
+	for range logged {
+		log.Error(context.Background(), errors.New("flood"))
+	}
+	close(in.block)
+	l.Flush()
+
+	events := in.received(t)
+	assert.NotEmpty(t, events)
+	assert.Less(t, len(events), logged)
+}
+
+func TestClose(t *testing.T) {
+	in, l := setup(t)
+
+	log.Error(context.Background(), errors.New("before close"))
+	l.Close()
+	l.Close()
+	// Events logged after closing are dropped
+	log.Error(context.Background(), errors.New("after close"))
+	l.Flush()
+
+	events := in.received(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, "before close", events[0].Message)
+}
+
+func TestFlushWhileLogging(t *testing.T) {
+	in, l := setup(t)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 10 {
+				log.Error(context.Background(), errors.New("concurrent"))
+				l.Flush()
+			}
+		})
+	}
+	wg.Wait()
+	l.Flush()
+	assert.NotEmpty(t, in.received(t))
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name   string
+		dsn    string
+		expErr bool
+	}{
+		{name: "valid", dsn: "https://key@o1.ingest.sentry.io/123"},
+		{name: "with path", dsn: "https://key@sentry.example.com/sentry/123"},
+		{name: "no key", dsn: "https://o1.ingest.sentry.io/123", expErr: true},
+		{name: "no project", dsn: "https://key@o1.ingest.sentry.io/", expErr: true},
+		{name: "invalid", dsn: "://", expErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := sentry.New(nil, tc.dsn)
+			if tc.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			l.Close()
+		})
+	}
+}
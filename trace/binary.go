@@ -1,11 +1,135 @@
 package trace
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
 )
 
 // CurrentBinary gives the name of the executable running the current Go code
 func CurrentBinary() string {
 	return filepath.Base(os.Args[0])
 }
+
+// Identity describes the process running the current Go code.
+// It's recorded on errors alongside their stack traces, so we can tell
+// which process each part of a trace came from.
+type Identity struct {
+	// Service is the name of the service, it defaults to CurrentBinary
+	Service string
+	// Version is the version of the service, it defaults to the main module version
+	Version string
+	// Revision is the VCS revision the service was built from, it defaults
+	// to the revision from the build info
+	Revision string
+	// Host is the host or pod that the process is running on, there is no default
+	Host string
+}
+
+// String formats the identity as "service@version (host)", the revision is
+// used when there's no version and empty parts are left out.
+func (i Identity) String() string {
+	var sb strings.Builder
+	sb.WriteString(i.Service)
+	if i.Version != "" && i.Version != "(devel)" {
+		sb.WriteString("@" + i.Version)
+	} else if i.Revision != "" {
+		sb.WriteString("@" + shortRevision(i.Revision))
+	}
+	if i.Host != "" {
+		sb.WriteString(" (" + i.Host + ")")
+	}
+	return sb.String()
+}
+
+func shortRevision(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+var (
+	identityMu  sync.RWMutex
+	identitySet bool
+	identity    = DefaultIdentity()
+	identityStr = identity.String()
+)
+
+// DefaultIdentity returns the identity of this process using
+// the binary name and its build info
+func DefaultIdentity() Identity {
+	id := Identity{Service: CurrentBinary()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return id
+	}
+	id.Version = info.Main.Version
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			id.Revision = s.Value
+		}
+	}
+	return id
+}
+
+// SetIdentity sets the identity of this process, it should be called once at startup.
+// Any fields which aren't set are populated from DefaultIdentity.
+func SetIdentity(id Identity) {
+	identityMu.Lock()
+	defer identityMu.Unlock()
+	if identitySet {
+		panic(fmt.Sprintln("identity has already been set", identity, id))
+	}
+	setIdentity(id)
+	identitySet = true
+}
+
+// SetIdentityForTesting sets the identity of this process until the end of the test,
+// SetIdentity may be called again once the test has finished.
+func SetIdentityForTesting(t testing.TB, id Identity) {
+	identityMu.Lock()
+	defer identityMu.Unlock()
+	oldID, oldStr, oldSet := identity, identityStr, identitySet
+	t.Cleanup(func() {
+		identityMu.Lock()
+		defer identityMu.Unlock()
+		identity, identityStr, identitySet = oldID, oldStr, oldSet
+	})
+	setIdentity(id)
+}
+
+// setIdentity must be called with identityMu held
+func setIdentity(id Identity) {
+	def := DefaultIdentity()
+	if id.Service == "" {
+		id.Service = def.Service
+	}
+	if id.Version == "" {
+		id.Version = def.Version
+	}
+	if id.Revision == "" {
+		id.Revision = def.Revision
+	}
+	identity = id
+	identityStr = id.String()
+}
+
+// CurrentIdentity returns the identity of this process, see SetIdentity
+func CurrentIdentity() Identity {
+	identityMu.RLock()
+	defer identityMu.RUnlock()
+	return identity
+}
+
+// CurrentProcess returns the formatted identity of this process,
+// this is recorded on errors and shown between traces from different processes.
+func CurrentProcess() string {
+	identityMu.RLock()
+	defer identityMu.RUnlock()
+	return identityStr
+}
@@ -0,0 +1,63 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityString(t *testing.T) {
+	testCases := []struct {
+		name   string
+		id     Identity
+		expStr string
+	}{
+		{name: "empty"},
+		{name: "service only", id: Identity{Service: "api"}, expStr: "api"},
+		{name: "version", id: Identity{Service: "api", Version: "v1.2.3", Revision: "abc"}, expStr: "api@v1.2.3"},
+		{name: "devel uses revision", id: Identity{Service: "api", Version: "(devel)", Revision: "abc"}, expStr: "api@abc"},
+		{
+			name:   "long revision is shortened",
+			id:     Identity{Service: "api", Revision: "7835f813f4da1c2b3e4f5a6b7c8d9e0f12345678"},
+			expStr: "api@7835f813f4da",
+		},
+		{
+			name:   "host",
+			id:     Identity{Service: "api", Version: "v1.2.3", Host: "api-5d8f7c-x2x4z"},
+			expStr: "api@v1.2.3 (api-5d8f7c-x2x4z)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expStr, tc.id.String())
+		})
+	}
+}
+
+func TestDefaultIdentity(t *testing.T) {
+	id := DefaultIdentity()
+	assert.Equal(t, CurrentBinary(), id.Service)
+	assert.Empty(t, id.Host)
+	assert.Equal(t, id, CurrentIdentity())
+}
+
+func TestSetIdentity(t *testing.T) {
+	SetIdentityForTesting(t, Identity{Service: "api", Host: "pod-1"})
+	def := DefaultIdentity()
+	assert.Equal(t, Identity{
+		Service:  "api",
+		Version:  def.Version,
+		Revision: def.Revision,
+		Host:     "pod-1",
+	}, CurrentIdentity())
+	assert.Equal(t, CurrentIdentity().String(), CurrentProcess())
+
+	// SetIdentityForTesting restores identitySet as well, so SetIdentity
+	// can be called here without leaking into other tests
+	SetIdentityForTesting(t, Identity{})
+	SetIdentity(Identity{Service: "once"})
+	assert.Equal(t, "once", CurrentIdentity().Service)
+	assert.Panics(t, func() {
+		SetIdentity(Identity{Service: "twice"})
+	})
+}
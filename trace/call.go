@@ -0,0 +1,89 @@
+package trace
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/go-stack/stack"
+)
+
+// call is a call in a captured stack trace. The stack.Call is only set when
+// the trace was captured for a StackConfig with FormatStack, which needs it.
+type call struct {
+	frame runtime.Frame
+	call  stack.Call
+}
+
+// funcName returns the name of the function without its package, like "%n" for stack.Call
+func funcName(f runtime.Frame) string {
+	name := f.Function
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i != -1 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// pkgPath returns the import path of the function's package, like "%+k" for stack.Call
+func pkgPath(f runtime.Frame) string {
+	name := f.Function
+	start := strings.LastIndex(name, "/") + 1
+	if i := strings.Index(name[start:], "."); i != -1 {
+		return name[:start+i]
+	}
+	return name
+}
+
+// pkgFile returns the file prefixed with its package path, like "%+s" for stack.Call
+func pkgFile(f runtime.Frame) string {
+	file := f.File
+	if sep := strings.LastIndex(file, "/"); sep != -1 {
+		file = file[strings.LastIndex(file[:sep], "/")+1:]
+	}
+	end := strings.LastIndex(f.Function, "/")
+	if end == -1 {
+		return file
+	}
+	return f.Function[:end] + "/" + file
+}
+
+// fileLine returns the file prefixed with its package path and the line, like "%+v" for stack.Call
+func fileLine(f runtime.Frame) string {
+	return pkgFile(f) + ":" + strconv.Itoa(f.Line)
+}
+
+// goroot is the directory of the standard library's source
+var goroot = func() string {
+	var pcs [1]uintptr
+	runtime.Callers(0, pcs[:])
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+	// f is runtime.Callers, in $GOROOT/src/runtime/
+	dir := f.File[:max(strings.LastIndex(f.File, "/runtime/"), 0)]
+	if runtime.GOOS == "windows" {
+		dir = strings.ToLower(dir)
+	}
+	return dir + "/"
+}()
+
+// inGoroot returns true if f is a call in the standard library or the test main
+func inGoroot(f runtime.Frame) bool {
+	file := f.File
+	if len(file) == 0 || file[0] == '?' {
+		return true
+	}
+	if runtime.GOOS == "windows" {
+		file = strings.ToLower(file)
+	}
+	return strings.HasPrefix(file, goroot) || strings.HasSuffix(file, "/_testmain.go")
+}
+
+// trimRuntime removes the calls in the standard library from the end of the stack trace
+func trimRuntime(calls []call) []call {
+	for len(calls) > 0 && inGoroot(calls[len(calls)-1].frame) {
+		calls = calls[:len(calls)-1]
+	}
+	return calls
+}
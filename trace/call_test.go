@@ -0,0 +1,46 @@
+package trace
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-stack/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+type callRepo struct{}
+
+func (*callRepo) get() stack.Call {
+	return stack.Caller(0)
+}
+
+func TestCallFormatting(t *testing.T) {
+	var r genericRepo[int]
+	closure := func() stack.Call { return stack.Caller(0) }
+	testCases := []struct {
+		name string
+		call stack.Call
+	}{
+		{name: "function", call: stack.Caller(0)},
+		{name: "method", call: new(callRepo).get()},
+		{name: "generic method", call: r.get()},
+		{name: "generic function", call: genericFunc[string]()},
+		{name: "closure", call: closure()},
+		{name: "standard library", call: stack.Trace()[1]},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := tc.call.Frame()
+			assert.Equal(t, fmt.Sprintf("%n", tc.call), funcName(f))
+			assert.Equal(t, fmt.Sprintf("%+k", tc.call), pkgPath(f))
+			assert.Equal(t, fmt.Sprintf("%+s", tc.call), pkgFile(f))
+			assert.Equal(t, fmt.Sprintf("%+v", tc.call), fileLine(f))
+		})
+	}
+}
+
+func TestTrimRuntime(t *testing.T) {
+	cs := stack.Trace()
+	assert.Len(t, trimRuntime(fromCallStack(cs)), len(cs.TrimRuntime()))
+	assert.Less(t, len(cs.TrimRuntime()), len(cs))
+}
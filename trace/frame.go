@@ -0,0 +1,99 @@
+package trace
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Frame is a single call in a stack trace, split into its components
+// so that it can be linked back to the source code.
+type Frame struct {
+	// Function is the name of the function without its package,
+	// including the receiver for methods, e.g. (*Server).Handle
+	Function string `json:"function"`
+	// Package is the full import path of the function's package
+	Package string `json:"package"`
+	// File is the name of the source file, prefixed with its package path,
+	// e.g. github.com/luno/jettison/errors/errors.go
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Module is the path of the module that the package belongs to
+	// and Version is the version of that module, both are only
+	// set when they are available from the binary's build info
+	Module  string `json:"module,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Link is a URL to the source code, created using StackConfig.LinkTemplate
+	Link string `json:"link,omitempty"`
+}
+
+func makeFrame(rf runtime.Frame, config StackConfig) Frame {
+	f := Frame{
+		Function: funcName(rf),
+		Package:  pkgPath(rf),
+		File:     pkgFile(rf),
+		Line:     rf.Line,
+	}
+	b := currentBuild()
+	if m, ok := b.findModule(f.Package); ok {
+		f.Module = m.Path
+		f.Version = m.Version
+		f.Link = b.link(config.LinkTemplate, f, m)
+	}
+	return f
+}
+
+// build holds the modules from the binary's build info
+type build struct {
+	modules []*debug.Module
+	// revision is the VCS revision of the main module
+	revision string
+}
+
+var currentBuild = sync.OnceValue(func() *build {
+	var b build
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return &b
+	}
+	b.modules = append(b.modules, &info.Main)
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = &debug.Module{Path: dep.Path, Version: dep.Replace.Version, Sum: dep.Replace.Sum}
+		}
+		b.modules = append(b.modules, dep)
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			b.revision = s.Value
+		}
+	}
+	return &b
+})
+
+// SetRevisionForTesting sets the VCS revision of the main module, which is
+// used for {ref} in links, until the end of the test
+func SetRevisionForTesting(t testing.TB, revision string) {
+	current := currentBuild
+	t.Cleanup(func() { currentBuild = current })
+	b := *current()
+	b.revision = revision
+	currentBuild = func() *build { return &b }
+}
+
+// findModule returns the module from the build info that contains pkg
+func (b *build) findModule(pkg string) (*debug.Module, bool) {
+	var found *debug.Module
+	for _, m := range b.modules {
+		if m.Path == "" || (pkg != m.Path && !strings.HasPrefix(pkg, m.Path+"/")) {
+			continue
+		}
+		// Prefer the most specific module, for nested modules
+		if found == nil || len(m.Path) > len(found.Path) {
+			found = m
+		}
+	}
+	return found, found != nil
+}
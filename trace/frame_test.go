@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrames(t *testing.T) {
+	tr := Capture(0, StackConfig{IncludeFrames: true})
+	frames := tr.Frames()
+	require.Len(t, frames, len(tr.Lines()))
+
+	assert.Equal(t, "TestFrames", frames[0].Function)
+	assert.Equal(t, "github.com/luno/jettison/trace", frames[0].Package)
+	assert.Equal(t, "github.com/luno/jettison/trace/frame_test.go", frames[0].File)
+	assert.Equal(t, 11, frames[0].Line)
+	assert.Equal(t, "github.com/luno/jettison", frames[0].Module)
+
+	assert.Equal(t, "tRunner", frames[1].Function)
+	assert.Equal(t, "testing", frames[1].Package)
+	assert.Empty(t, frames[1].Module)
+}
+
+func TestFramesNotIncluded(t *testing.T) {
+	tr := Capture(0, StackConfig{TrimRuntime: true})
+	assert.NotEmpty(t, tr.Lines())
+	assert.Empty(t, tr.Frames())
+}
+
+func TestFramesFiltered(t *testing.T) {
+	tr := Capture(0, StackConfig{
+		PackagesShown: []string{PackagePath(StackConfig{})},
+		IncludeFrames: true,
+	})
+	frames := tr.Frames()
+	require.Len(t, frames, 1)
+	assert.Equal(t, "TestFramesFiltered", frames[0].Function)
+}
+
+func TestFindModule(t *testing.T) {
+	testCases := []struct {
+		name   string
+		pkg    string
+		expMod string
+	}{
+		{name: "main module", pkg: "github.com/luno/jettison", expMod: "github.com/luno/jettison"},
+		{name: "main module package", pkg: "github.com/luno/jettison/trace", expMod: "github.com/luno/jettison"},
+		{name: "dependency", pkg: "github.com/go-stack/stack", expMod: "github.com/go-stack/stack"},
+		{name: "prefix of another module", pkg: "github.com/luno/jettisonx"},
+		{name: "std lib", pkg: "net/http"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, ok := currentBuild().findModule(tc.pkg)
+			if tc.expMod == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tc.expMod, m.Path)
+		})
+	}
+}
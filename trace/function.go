@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/go-stack/stack"
+)
+
+// ReadableFunction returns the name of the function of call, without its package,
+// in a form that's easier to read than the name given by the runtime.
+//
+// Closures are annotated with the function that they're defined in and the line
+// they're defined on, when it's known, e.g. "handle.func1" is formatted as
+// "handle.func1 (closure in handle, line 32)".
+//
+// The runtime doesn't record the type arguments of generic functions, instead
+// their names contain "[...]". This is removed and the function is annotated
+// as generic, e.g. "(*Repo[...]).Get" is formatted as "(*Repo).Get (generic)".
+func ReadableFunction(call stack.Call) string {
+	return readableFunction(call.Frame())
+}
+
+func readableFunction(f runtime.Frame) string {
+	name := funcName(f)
+
+	var notes []string
+	if strings.Contains(name, "[...]") {
+		name = strings.ReplaceAll(name, "[...]", "")
+		notes = append(notes, "generic")
+	}
+
+	if outer, ok := enclosingFunction(name); ok {
+		note := "closure in " + outer
+		if line := definedLine(f); line > 0 {
+			note += fmt.Sprintf(", line %d", line)
+		}
+		notes = append(notes, note)
+	}
+
+	if len(notes) == 0 {
+		return name
+	}
+	return name + " (" + strings.Join(notes, ", ") + ")"
+}
+
+// enclosingFunction returns the function that a closure is defined in,
+// closure names are suffixed with parts like ".func1", ".2", ".gowrap1" or ".deferwrap1"
+func enclosingFunction(name string) (string, bool) {
+	parts := strings.Split(name, ".")
+	n := len(parts)
+	for n > 1 && isClosurePart(parts[n-1]) {
+		n--
+	}
+	if n == len(parts) {
+		return "", false
+	}
+	outer := strings.Join(parts[:n], ".")
+	switch outer {
+	case "init", "glob.":
+		// Closures assigned to package level variables
+		return "package variable", true
+	}
+	return outer, true
+}
+
+func isClosurePart(s string) bool {
+	for _, prefix := range []string{"func", "gowrap", "deferwrap"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			s = rest
+			break
+		}
+	}
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// definedLine returns the line that the function of f starts on,
+// or 0 if the function was inlined and so its start isn't known
+func definedLine(f runtime.Frame) int {
+	if f.Func == nil || f.Func.Name() != f.Function {
+		return 0
+	}
+	_, line := f.Func.FileLine(f.Entry)
+	return line
+}
@@ -0,0 +1,76 @@
+package trace
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/go-stack/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnclosingFunction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expOuter string
+		expOK    bool
+	}{
+		{name: "handle"},
+		{name: "(*Server).Handle"},
+		{name: "handle.func1", expOuter: "handle", expOK: true},
+		{name: "handle.func1.2", expOuter: "handle", expOK: true},
+		{name: "handle.func1.func3", expOuter: "handle", expOK: true},
+		{name: "(*Server).Handle.func1", expOuter: "(*Server).Handle", expOK: true},
+		{name: "handle.gowrap1", expOuter: "handle", expOK: true},
+		{name: "handle.deferwrap2", expOuter: "handle", expOK: true},
+		{name: "init.func2", expOuter: "package variable", expOK: true},
+		{name: "glob..func1", expOuter: "package variable", expOK: true},
+		{name: "function"},
+		{name: "func1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outer, ok := enclosingFunction(tc.name)
+			assert.Equal(t, tc.expOK, ok)
+			assert.Equal(t, tc.expOuter, outer)
+		})
+	}
+}
+
+type genericRepo[T any] struct{}
+
+//go:noinline
+func (r *genericRepo[T]) get() stack.Call {
+	return stack.Caller(0)
+}
+
+//go:noinline
+func genericFunc[T any]() stack.Call {
+	return stack.Caller(0)
+}
+
+func TestReadableFunction(t *testing.T) {
+	assert.Equal(t, "TestReadableFunction", ReadableFunction(stack.Caller(0)))
+
+	var r genericRepo[int]
+	assert.Equal(t, "(*genericRepo).get (generic)", ReadableFunction(r.get()))
+	assert.Equal(t, "genericFunc (generic)", ReadableFunction(genericFunc[string]()))
+
+	_, _, line, _ := runtime.Caller(0)
+	calls := make(chan stack.Call)
+	go func() {
+		calls <- stack.Caller(0)
+	}()
+	exp := fmt.Sprintf("TestReadableFunction.func1 (closure in TestReadableFunction, line %d)", line+2)
+	assert.Equal(t, exp, ReadableFunction(<-calls))
+}
+
+func TestReadableFunctionsConfig(t *testing.T) {
+	config := StackConfig{
+		ReadableFunctions: true,
+		PackagesShown:     []string{PackagePath(StackConfig{})},
+	}
+	var r genericRepo[int]
+	c := r.get()
+	assert.Equal(t, fmt.Sprintf("%+v (*genericRepo).get (generic)", c), config.formatStackLine(call{frame: c.Frame()}))
+}
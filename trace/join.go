@@ -1,26 +1,86 @@
 package trace
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
+
+// Section is a stack trace captured in a single binary
+type Section struct {
+	Binary string
+	Trace  []string
+	// Frames are the structured calls of Trace, they may be empty
+	Frames []Frame
+	// Goroutine is the id of the goroutine the trace was captured on, if known.
+	// When consecutive sections are from goroutines in the same binary, they are
+	// separated by a goroutine boundary rather than a hop between binaries,
+	// and the calls which they share are only included once.
+	Goroutine int64
+}
 
 type Merge struct {
-	traces   [][]string
-	binaries []string
+	sections []Section
 }
 
 func (m *Merge) Add(trace []string, binary string) {
-	m.traces = append(m.traces, trace)
-	m.binaries = append(m.binaries, binary)
+	m.AddSection(Section{Binary: binary, Trace: trace})
+}
+
+func (m *Merge) AddSection(s Section) {
+	m.sections = append(m.sections, s)
 }
 
 func (m *Merge) FullTrace() []string {
 	var ret []string
-	for i := len(m.traces) - 1; i >= 0; i-- {
-		ret = append(ret, m.traces[i]...)
-		if i > 0 {
-			ret = append(ret,
-				fmt.Sprintf("%s -> %s", m.binaries[i-1], m.binaries[i]),
-			)
+	for i := len(m.sections) - 1; i >= 0; i-- {
+		if i == 0 {
+			ret = append(ret, m.sections[i].Trace...)
+			break
+		}
+		ret = append(ret, InnerTrace(m.sections[i-1], m.sections[i])...)
+	}
+	return ret
+}
+
+// InnerTrace returns the lines of inner as they appear in a merged trace when it
+// was wrapped by outer. The calls shared with outer are removed when both are
+// from the same process and the lines end with the hop or goroutine boundary between them.
+func InnerTrace(outer, inner Section) []string {
+	if !sameProcess(outer, inner) {
+		ret := slices.Clip(inner.Trace)
+		return append(ret, fmt.Sprintf("%s -> %s", outer.Binary, inner.Binary))
+	}
+	ret := slices.Clip(trimShared(inner.Trace, outer.Trace))
+	if outer.Goroutine == inner.Goroutine {
+		return append(ret, fmt.Sprintf("goroutine %d", inner.Goroutine))
+	}
+	return append(ret, fmt.Sprintf("goroutine %d -> goroutine %d", outer.Goroutine, inner.Goroutine))
+}
+
+// FullFrames returns the frames of every section in the same order as FullTrace,
+// without any lines marking the hops or goroutine boundaries
+func (m *Merge) FullFrames() []Frame {
+	var ret []Frame
+	for i := len(m.sections) - 1; i >= 0; i-- {
+		s := m.sections[i]
+		if i > 0 && sameProcess(m.sections[i-1], s) {
+			ret = append(ret, trimShared(s.Frames, m.sections[i-1].Frames)...)
+		} else {
+			ret = append(ret, s.Frames...)
 		}
 	}
 	return ret
 }
+
+func sameProcess(a, b Section) bool {
+	return a.Goroutine != 0 && b.Goroutine != 0 && a.Binary == b.Binary
+}
+
+// trimShared removes the calls at the bottom of trace which are also at the bottom of other
+func trimShared[T comparable](trace, other []T) []T {
+	n := len(trace)
+	for j := len(other); n > 0 && j > 0 && trace[n-1] == other[j-1]; j-- {
+		n--
+	}
+	return trace[:n]
+}
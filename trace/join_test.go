@@ -8,8 +8,9 @@ import (
 
 func TestMerge(t *testing.T) {
 	type trace struct {
-		trace  []string
-		binary string
+		trace     []string
+		binary    string
+		goroutine int64
 	}
 	testCases := []struct {
 		name         string
@@ -55,15 +56,95 @@ func TestMerge(t *testing.T) {
 				"from_a",
 			},
 		},
+		{
+			name: "identities in hops",
+			traces: []trace{
+				{trace: []string{"call"}, binary: Identity{Service: "api", Version: "v1.0.0", Host: "api-1"}.String()},
+				{trace: []string{"handle"}, binary: Identity{Service: "exchange", Revision: "abcdef", Host: "exchange-2"}.String()},
+			},
+			expFullTrace: []string{
+				"handle",
+				"api@v1.0.0 (api-1) -> exchange@abcdef (exchange-2)",
+				"call",
+			},
+		},
+		{
+			name: "goroutine boundary",
+			traces: []trace{
+				{trace: []string{"consume", "main"}, binary: "bin", goroutine: 1},
+				{trace: []string{"work", "worker"}, binary: "bin", goroutine: 7},
+			},
+			expFullTrace: []string{
+				"work",
+				"worker",
+				"goroutine 1 -> goroutine 7",
+				"consume",
+				"main",
+			},
+		},
+		{
+			name: "same goroutine removes shared calls",
+			traces: []trace{
+				{trace: []string{"wrap:10", "handle:20", "main:30"}, binary: "bin", goroutine: 1},
+				{trace: []string{"query:40", "lookup:50", "handle:21", "main:30"}, binary: "bin", goroutine: 1},
+			},
+			expFullTrace: []string{
+				"query:40",
+				"lookup:50",
+				"handle:21",
+				"goroutine 1",
+				"wrap:10",
+				"handle:20",
+				"main:30",
+			},
+		},
+		{
+			name: "goroutines across binaries",
+			traces: []trace{
+				{trace: []string{"from_a"}, binary: "a", goroutine: 1},
+				{trace: []string{"from_b"}, binary: "b"},
+				{trace: []string{"from_b_worker"}, binary: "b", goroutine: 5},
+			},
+			expFullTrace: []string{
+				"from_b_worker",
+				"b -> b",
+				"from_b",
+				"a -> b",
+				"from_a",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			var m Merge
 			for _, tr := range tc.traces {
-				m.Add(tr.trace, tr.binary)
+				m.AddSection(Section{Binary: tr.binary, Trace: tr.trace, Goroutine: tr.goroutine})
 			}
 			assert.Equal(t, tc.expFullTrace, m.FullTrace())
 		})
 	}
 }
+
+func TestMergeFrames(t *testing.T) {
+	frame := func(fn string, line int) Frame {
+		return Frame{Function: fn, Package: "main", File: "main/main.go", Line: line}
+	}
+	var m Merge
+	m.AddSection(Section{
+		Binary:    "bin",
+		Trace:     []string{"wrap", "main"},
+		Frames:    []Frame{frame("wrap", 1), frame("main", 2)},
+		Goroutine: 1,
+	})
+	m.AddSection(Section{
+		Binary:    "bin",
+		Trace:     []string{"create", "main"},
+		Frames:    []Frame{frame("create", 3), frame("main", 2)},
+		Goroutine: 1,
+	})
+	m.Add([]string{"remote"}, "other")
+
+	assert.Equal(t, []string{"remote", "bin -> other", "create", "goroutine 1", "wrap", "main"}, m.FullTrace())
+	assert.Equal(t, []Frame{frame("create", 3), frame("wrap", 1), frame("main", 2)}, m.FullFrames())
+}
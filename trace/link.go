@@ -0,0 +1,61 @@
+package trace
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// link returns a link to the source of f, which is in module m
+func (b *build) link(template string, f Frame, m *debug.Module) string {
+	if template == "" {
+		return ""
+	}
+	ref := b.ref(m)
+	if ref == "" {
+		return ""
+	}
+	path, ok := strings.CutPrefix(f.File, m.Path+"/")
+	if !ok {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{module}", m.Path,
+		"{ref}", ref,
+		"{path}", path,
+		"{line}", strconv.Itoa(f.Line),
+	).Replace(template)
+}
+
+// ref returns a VCS reference to the source code of m.
+// For the main module this is the revision it was built from, otherwise
+// it's the module version or the revision from a pseudo-version.
+func (b *build) ref(m *debug.Module) string {
+	if len(b.modules) > 0 && m == b.modules[0] {
+		return b.revision
+	}
+	return versionRef(m.Version)
+}
+
+// versionRef returns the ref for a module version, pseudo-versions like
+// v0.0.0-20240903120638-7835f813f4da reference the revision at the end
+func versionRef(version string) string {
+	if version == "" || version == "(devel)" {
+		return ""
+	}
+	v, _, _ := strings.Cut(version, "+")
+	parts := strings.Split(v, "-")
+	if len(parts) < 3 {
+		return v
+	}
+	rev := parts[len(parts)-1]
+	ts := parts[len(parts)-2]
+	// The timestamp may be prefixed, e.g. v1.2.4-0.20240101000000-abcdef123456
+	if i := strings.LastIndex(ts, "."); i >= 0 {
+		ts = ts[i+1:]
+	}
+	if len(ts) != 14 || len(rev) != 12 {
+		return v
+	}
+	return rev
+}
@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const githubTemplate = "https://{module}/blob/{ref}/{path}#L{line}"
+
+func TestVersionRef(t *testing.T) {
+	testCases := []struct {
+		version string
+		expRef  string
+	}{
+		{version: ""},
+		{version: "(devel)"},
+		{version: "v1.2.3", expRef: "v1.2.3"},
+		{version: "v1.2.3+incompatible", expRef: "v1.2.3"},
+		{version: "v1.2.3-rc.1", expRef: "v1.2.3-rc.1"},
+		{version: "v0.0.0-20240903120638-7835f813f4da", expRef: "7835f813f4da"},
+		{version: "v1.2.4-0.20240903120638-7835f813f4da", expRef: "7835f813f4da"},
+		{version: "v1.2.4-pre.0.20240903120638-7835f813f4da+dirty", expRef: "7835f813f4da"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			assert.Equal(t, tc.expRef, versionRef(tc.version))
+		})
+	}
+}
+
+func TestBuildLink(t *testing.T) {
+	b := &build{
+		modules: []*debug.Module{
+			{Path: "github.com/luno/jettison", Version: "(devel)"},
+			{Path: "github.com/go-stack/stack", Version: "v1.8.1"},
+			{Path: "golang.org/x/xerrors", Version: "v0.0.0-20240903120638-7835f813f4da"},
+		},
+		revision: "abcdef",
+	}
+	testCases := []struct {
+		name     string
+		template string
+		frame    Frame
+		expLink  string
+	}{
+		{
+			name:     "main module",
+			template: githubTemplate,
+			frame:    Frame{Package: "github.com/luno/jettison/trace", File: "github.com/luno/jettison/trace/link.go", Line: 12},
+			expLink:  "https://github.com/luno/jettison/blob/abcdef/trace/link.go#L12",
+		},
+		{
+			name:     "dependency",
+			template: githubTemplate,
+			frame:    Frame{Package: "github.com/go-stack/stack", File: "github.com/go-stack/stack/stack.go", Line: 1},
+			expLink:  "https://github.com/go-stack/stack/blob/v1.8.1/stack.go#L1",
+		},
+		{
+			name:     "pseudo-version",
+			template: "{ref}:{path}",
+			frame:    Frame{Package: "golang.org/x/xerrors", File: "golang.org/x/xerrors/wrap.go", Line: 1},
+			expLink:  "7835f813f4da:wrap.go",
+		},
+		{
+			name:  "no template",
+			frame: Frame{Package: "github.com/luno/jettison/trace", File: "github.com/luno/jettison/trace/link.go", Line: 12},
+		},
+		{
+			name:     "file outside module",
+			template: githubTemplate,
+			frame:    Frame{Package: "github.com/luno/jettison/trace", File: "/tmp/link.go", Line: 12},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, ok := b.findModule(tc.frame.Package)
+			require.True(t, ok)
+			assert.Equal(t, tc.expLink, b.link(tc.template, tc.frame, m))
+		})
+	}
+
+	t.Run("main module without revision", func(t *testing.T) {
+		noRev := &build{modules: b.modules}
+		f := Frame{Package: "github.com/luno/jettison/trace", File: "github.com/luno/jettison/trace/link.go", Line: 12}
+		assert.Empty(t, noRev.link(githubTemplate, f, noRev.modules[0]))
+	})
+}
+
+func TestLinksInTrace(t *testing.T) {
+	SetRevisionForTesting(t, "abcdef")
+
+	config := StackConfig{
+		PackagesShown: []string{PackagePath(StackConfig{})},
+		IncludeFrames: true,
+		LinkTemplate:  githubTemplate,
+	}
+	tr := Capture(0, config)
+	link := "https://github.com/luno/jettison/blob/abcdef/trace/link_test.go#L100"
+	assert.Equal(t, []string{link + " TestLinksInTrace"}, tr.Lines())
+	require.Len(t, tr.Frames(), 1)
+	assert.Equal(t, link, tr.Frames()[0].Link)
+	assert.Equal(t, []string{"github.com/luno/jettison/trace/link_test.go:100 TestLinksInTrace"}, tr.UnlinkedLines())
+
+	assert.Equal(t, "https://github.com/luno/jettison/blob/abcdef/trace/link_test.go#L108",
+		GetSourceCodeRef(0, config))
+}
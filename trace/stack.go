@@ -3,6 +3,7 @@ package trace
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
 
 	"github.com/go-stack/stack"
@@ -18,6 +19,9 @@ func PackagePath(a any) string {
 type StackConfig struct {
 	// RemoveLambdas will remove anonymous functions from the call stack
 	RemoveLambdas bool
+	// ReadableFunctions will format the function names in the default stack lines
+	// using ReadableFunction, which annotates closures and generic functions.
+	ReadableFunctions bool
 	// PackagesShown, if not empty, will limit the call stack to functions from these packages
 	PackagesShown []string
 	// PackagesHidden will remove any calls in the stack from these packages
@@ -25,6 +29,24 @@ type StackConfig struct {
 	PackagesHidden []string
 	// TrimRuntime will remove entries from the Go runtime
 	TrimRuntime bool
+	// TrackGoroutines will record the goroutine that each stack trace was captured on.
+	// This lets errors.Wrap add another stack trace when an error is passed between goroutines.
+	TrackGoroutines bool
+	// IncludeFrames will make structured frames available alongside the formatted stack trace,
+	// these are included in logs and passed over gRPC, see Frame
+	IncludeFrames bool
+	// LinkTemplate, if set, is used to create links to the source code of calls in the stack.
+	// The links replace file references in the default stack lines and source code references,
+	// and are added to frames.
+	// The template can contain these placeholders:
+	//   - {module} the path of the module, e.g. github.com/luno/jettison
+	//   - {ref} the VCS revision of the main module or the version of other modules
+	//   - {path} the path of the file relative to the module root
+	//   - {line} the line number
+	//
+	// For example "https://{module}/blob/{ref}/{path}#L{line}" will link to GitHub.
+	// A link is only created when the module and ref can be found in the binary's build info.
+	LinkTemplate string
 
 	// FormatStack is the format for lines in the stack trace
 	// The default will print the source reference and the function name
@@ -33,9 +55,9 @@ type StackConfig struct {
 	FormatReference func(stack.Call) string
 }
 
-func (c StackConfig) shouldKeepCall(call stack.Call) bool {
+func (c StackConfig) shouldKeepCall(f runtime.Frame) bool {
 	if c.RemoveLambdas {
-		fnName := fmt.Sprintf("%n", call)
+		fnName := funcName(f)
 		if strings.Contains(fnName, ".func") {
 			return false
 		}
@@ -43,7 +65,7 @@ func (c StackConfig) shouldKeepCall(call stack.Call) bool {
 	if len(c.PackagesShown) == 0 && len(c.PackagesHidden) == 0 {
 		return true
 	}
-	pkgName := fmt.Sprintf("%+k", call)
+	pkgName := pkgPath(f)
 	for _, p := range c.PackagesShown {
 		if strings.HasPrefix(pkgName, p) {
 			return true
@@ -57,35 +79,68 @@ func (c StackConfig) shouldKeepCall(call stack.Call) bool {
 	return len(c.PackagesShown) == 0
 }
 
-func (c StackConfig) formatStackLine(call stack.Call) string {
+func (c StackConfig) formatStackLine(cl call) string {
 	if c.FormatStack != nil {
-		return c.FormatStack(call)
+		return c.FormatStack(cl.call)
 	}
-	return fmt.Sprintf("%+v %n", call, call)
+	fn := funcName(cl.frame)
+	if c.ReadableFunctions {
+		fn = readableFunction(cl.frame)
+	}
+	if link := c.link(cl.frame); link != "" {
+		return link + " " + fn
+	}
+	return fileLine(cl.frame) + " " + fn
 }
 
 func (c StackConfig) formatReference(ref stack.Call) string {
 	if c.FormatReference != nil {
 		return c.FormatReference(ref)
 	}
+	if link := c.Link(ref); link != "" {
+		return link
+	}
 	return fmt.Sprintf("%+v", ref)
 }
 
+// Link returns a link to the source code of call using LinkTemplate.
+// An empty string is returned if there's no template or the link can't be made.
+// This can be used to add links in custom FormatStack and FormatReference functions.
+func (c StackConfig) Link(call stack.Call) string {
+	return c.link(call.Frame())
+}
+
+func (c StackConfig) link(f runtime.Frame) string {
+	if c.LinkTemplate == "" {
+		return ""
+	}
+	return makeFrame(f, c).Link
+}
+
 const maxDepth = 64
 
 // GetStackTrace returns a rendered stacktrace of the calling code, skipping
 // `skip` frames in the stack prior to this function
 func GetStackTrace(skip int, config StackConfig) []string {
-	var res []string
-	trace := stack.Trace()
-	if config.TrimRuntime {
-		trace = trace.TrimRuntime()
-	}
-	for _, c := range trace[skip+1:] {
-		if !config.shouldKeepCall(c) {
+	return Capture(skip+1, config).Lines()
+}
+
+// GetPanicStackTrace returns a rendered stacktrace starting from where the
+// current goroutine panicked. It is intended to be called from a deferred
+// function that has recovered the panic. If no panic can be found in the
+// stack, this behaves like GetStackTrace.
+func GetPanicStackTrace(skip int, config StackConfig) []string {
+	return CapturePanic(skip+1, config).Lines()
+}
+
+// keptCalls returns the calls in trace which should be shown according to config
+func keptCalls(trace []call, config StackConfig) []call {
+	var res []call
+	for _, c := range trace {
+		if !config.shouldKeepCall(c.frame) {
 			continue
 		}
-		res = append(res, config.formatStackLine(c))
+		res = append(res, c)
 		if len(res) >= maxDepth {
 			break
 		}
@@ -93,7 +148,35 @@ func GetStackTrace(skip int, config StackConfig) []string {
 	return res
 }
 
+// trimPanic removes all calls up to and including the runtime's panic handling,
+// leaving the call that panicked at the top of the stack
+func trimPanic(trace []call) []call {
+	for i, c := range trace {
+		if c.frame.Function != "runtime.gopanic" {
+			continue
+		}
+		trace = trace[i+1:]
+		// Runtime errors (e.g. nil pointer dereferences) panic from within the runtime
+		for len(trace) > 0 && strings.HasPrefix(trace[0].frame.Function, "runtime.") {
+			trace = trace[1:]
+		}
+		return trace
+	}
+	return trace
+}
+
 // GetSourceCodeRef returns the callers source code reference
 func GetSourceCodeRef(skip int, config StackConfig) string {
 	return config.formatReference(stack.Caller(skip + 1))
 }
+
+// GetPanicSourceCodeRef returns the source code reference of the call that
+// caused the current goroutine to panic. If no panic can be found in the
+// stack, this behaves like GetSourceCodeRef.
+func GetPanicSourceCodeRef(skip int, config StackConfig) string {
+	trace := trimPanic(fromCallStack(stack.Trace()[skip+1:]))
+	if len(trace) == 0 {
+		return ""
+	}
+	return config.formatReference(trace[0].call)
+}
@@ -90,3 +90,48 @@ func TestPkgPath(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkGetStackTrace(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetStackTrace(0, StackConfig{TrimRuntime: true})
+	}
+}
+
+func BenchmarkCapture(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Capture(0, StackConfig{TrimRuntime: true})
+	}
+}
+
+func TestCaptureMatchesGetStackTrace(t *testing.T) {
+	config := StackConfig{TrimRuntime: true}
+	tr := Capture(0, config)
+	st := GetStackTrace(0, config)
+	// Only the line of the call differs
+	assert.Equal(t, st[1:], tr.Lines()[1:])
+	assert.Equal(t, tr.Lines(), tr.Lines())
+}
+
+func TestNilTrace(t *testing.T) {
+	var tr *Trace
+	assert.Nil(t, tr.Lines())
+}
+
+func TestCurrentGoroutine(t *testing.T) {
+	g := CurrentGoroutine()
+	assert.NotZero(t, g)
+	assert.Equal(t, g, CurrentGoroutine())
+
+	other := make(chan int64)
+	go func() { other <- CurrentGoroutine() }()
+	o := <-other
+	assert.NotZero(t, o)
+	assert.NotEqual(t, g, o)
+}
+
+func TestCaptureGoroutine(t *testing.T) {
+	assert.Zero(t, Capture(0, StackConfig{}).Goroutine())
+	assert.Equal(t, CurrentGoroutine(), Capture(0, StackConfig{TrackGoroutines: true}).Goroutine())
+}
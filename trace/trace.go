@@ -0,0 +1,189 @@
+package trace
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/go-stack/stack"
+)
+
+// Trace is a captured stack trace.
+// Capturing a Trace only records the program counters of the calls,
+// symbolising and formatting them is deferred until Lines is called.
+// This makes creating errors cheap when the trace is never used.
+// When StackConfig.FormatStack is set the calls are symbolised when they're
+// captured, as FormatStack is given a stack.Call for each of them.
+type Trace struct {
+	pcs []uintptr
+	// calls are captured instead of pcs when config.FormatStack
+	// is set, as it needs the stack.Call of each frame
+	calls     stack.CallStack
+	config    StackConfig
+	panicked  bool
+	goroutine int64
+
+	once   sync.Once
+	lines  []string
+	frames []Frame
+}
+
+// Capture records the stack trace of the calling code, skipping
+// `skip` frames in the stack prior to this function.
+// The trace is formatted using config when Lines is called.
+func Capture(skip int, config StackConfig) *Trace {
+	return capture(skip+1, config, false)
+}
+
+// CapturePanic records the stack trace from where the current goroutine panicked.
+// It is intended to be called from a deferred function that has recovered the panic.
+// If no panic can be found in the stack, this behaves like Capture.
+func CapturePanic(skip int, config StackConfig) *Trace {
+	return capture(skip+1, config, true)
+}
+
+func capture(skip int, config StackConfig, panicked bool) *Trace {
+	t := &Trace{config: config, panicked: panicked}
+	if config.FormatStack != nil {
+		// Skip capture itself
+		t.calls = stack.Trace()[skip+1:]
+	} else {
+		var buf [512]uintptr
+		// Include this call in the trace, runtime.CallersFrames needs
+		// the frame prior to the target in case it's runtime.sigpanic
+		n := runtime.Callers(skip+1, buf[:])
+		t.pcs = make([]uintptr, n)
+		copy(t.pcs, buf[:n])
+	}
+	if config.TrackGoroutines {
+		t.goroutine = CurrentGoroutine()
+	}
+	return t
+}
+
+// Goroutine returns the id of the goroutine the trace was captured on,
+// this is only recorded when StackConfig.TrackGoroutines is set, otherwise it returns 0.
+func (t *Trace) Goroutine() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.goroutine
+}
+
+// Lines returns the formatted stack trace, it is safe to call concurrently
+func (t *Trace) Lines() []string {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(t.resolve)
+	return t.lines
+}
+
+// Frames returns the structured calls in the stack trace,
+// these are the same calls as are formatted by Lines.
+// Frames are only available when StackConfig.IncludeFrames is set.
+// It is safe to call concurrently.
+func (t *Trace) Frames() []Frame {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(t.resolve)
+	return t.frames
+}
+
+// UnlinkedLines returns the stack trace formatted without StackConfig.LinkTemplate.
+// Links include the VCS revision, so unlike Lines these don't change with each
+// release, which makes them suitable for grouping errors, e.g. in a fingerprint.
+// When there's no LinkTemplate this is the same as Lines.
+func (t *Trace) UnlinkedLines() []string {
+	if t == nil || t.config.LinkTemplate == "" {
+		return t.Lines()
+	}
+	config := t.config
+	config.LinkTemplate = ""
+	return formatLines(t.keptCalls(), config)
+}
+
+func (t *Trace) resolve() {
+	calls := t.keptCalls()
+	if len(calls) == 0 {
+		return
+	}
+	t.lines = formatLines(calls, t.config)
+	if !t.config.IncludeFrames {
+		return
+	}
+	t.frames = make([]Frame, 0, len(calls))
+	for _, c := range calls {
+		t.frames = append(t.frames, makeFrame(c.frame, t.config))
+	}
+}
+
+// keptCalls returns the calls in the trace which are shown according to its config
+func (t *Trace) keptCalls() []call {
+	calls := t.resolveCalls()
+	if t.config.TrimRuntime {
+		calls = trimRuntime(calls)
+	}
+	if t.panicked {
+		calls = trimPanic(calls)
+	}
+	return keptCalls(calls, t.config)
+}
+
+func formatLines(calls []call, config StackConfig) []string {
+	if len(calls) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(calls))
+	for _, c := range calls {
+		lines = append(lines, config.formatStackLine(c))
+	}
+	return lines
+}
+
+func (t *Trace) resolveCalls() []call {
+	if t.calls != nil {
+		return fromCallStack(t.calls)
+	}
+	frames := runtime.CallersFrames(t.pcs)
+	cs := make([]call, 0, len(t.pcs))
+	// Skip the frame of capture itself
+	_, more := frames.Next()
+	for more {
+		var f runtime.Frame
+		f, more = frames.Next()
+		cs = append(cs, call{frame: f})
+	}
+	return cs
+}
+
+func fromCallStack(cs stack.CallStack) []call {
+	calls := make([]call, 0, len(cs))
+	for _, c := range cs {
+		calls = append(calls, call{frame: c.Frame(), call: c})
+	}
+	return calls
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// CurrentGoroutine returns the id of the calling goroutine, or 0 if it can't be determined.
+// Go doesn't expose goroutine ids, so this parses the header of runtime.Stack,
+// avoid calling it in performance sensitive code.
+func CurrentGoroutine() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The stack starts with "goroutine 123 [running]:"
+	b, ok := bytes.CutPrefix(buf[:n], goroutinePrefix)
+	if !ok {
+		return 0
+	}
+	b, _, _ = bytes.Cut(b, []byte(" "))
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}